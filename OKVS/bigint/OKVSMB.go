@@ -0,0 +1,229 @@
+package bigint
+
+import (
+	"io"
+	"math/big"
+	"sort"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// 定义System结构体
+type SystemB struct {
+	Pos   int
+	Row   *big.Int
+	Value *big.Int
+	Key   []byte
+}
+
+type OKVSB struct {
+	N    int //okvs存储的k-v长度
+	M    int //okvs的实际长度
+	W    int //随机块的长度
+	R    int // hashrange
+	P    []*big.Int
+	Seed []byte        // hash 种子，为空时等价于不加 key 的 blake2b
+	Tag  []byte        // 域分离标签
+	Rand io.Reader     // 非空时用它随机填充非主元位置，使 OKVS 看起来是均匀随机的
+	Hash okvs.HashMode // 起始位置的 hash 方式，零值是 Hash32，构造函数默认用 Hash64
+	// Workers 是计算 hash 使用的 goroutine 数，0 表示 GOMAXPROCS
+	Workers int
+	// L 是随机填充的字节数，value 不能比它长，0 表示 DefaultValueSize。它不会被序列化
+	L int
+}
+
+// DefaultValueSize 是 OKVSB 随机填充默认的字节数（256 位）
+const DefaultValueSize = 32
+
+// NewOKVSB 根据 n、扩张率和 W 构造 OKVSB，M = round(n*e)，R = M - W
+func NewOKVSB(n int, opts ...okvs.Option) (*OKVSB, error) {
+	c := okvs.NewConfig(opts...)
+	m, err := c.Size(n)
+	if err != nil {
+		return nil, err
+	}
+	r := &OKVSB{
+		N:       n,
+		M:       m,
+		W:       c.W,
+		R:       m - c.W,
+		P:       make([]*big.Int, m),
+		Seed:    c.Seed,
+		Tag:     c.Tag,
+		Rand:    c.Rand,
+		Hash:    c.Hash,
+		Workers: c.Workers,
+		L:       c.ValueSize,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate 检查参数是否一致
+func (r *OKVSB) Validate() error {
+	if err := common.CheckParams(r.N, r.M, r.W, r.R, len(r.P), r.Seed); err != nil {
+		return err
+	}
+	if r.L < 0 {
+		return common.ParamError("L = %d must not be negative", r.L)
+	}
+	return r.Hash.Check(r.R)
+}
+
+type KVB struct {
+	Key   []byte   //key
+	Value *big.Int //value
+}
+
+func (r *OKVSB) hash1(key []byte) int {
+	return r.Hash.Pos(r.Seed, r.Tag, key, r.R)
+}
+
+func (r *OKVSB) hash2(key []byte) *big.Int {
+	bandsize := r.W / 8
+	hashBytes := okvs.HashWithSeed(bandsize, r.Seed, r.Tag, key)
+	band := new(big.Int).SetBytes(hashBytes)
+	band = band.SetBit(band, r.W-1, 1)
+	return band
+}
+
+func (r *OKVSB) SetLine(i int, system *SystemB, kv *KVB) {
+	system.Pos = r.hash1(kv.Key)
+	system.Row = r.hash2(kv.Key)
+	if system.Row.BitLen() != r.W {
+		system.Row = system.Row.SetBit(system.Row, r.W-1, 0)
+	}
+	system.Value = kv.Value
+	system.Key = kv.Key
+}
+
+// ShiftRow 在第 k 行的主元列 pivi 处为 1 时把第 i 行异或进去。第 i 行在主元之前全为 0，
+// 所以右移 Pos 的差之后不会丢掉任何一位；两行的 Row 和 Value 都不会被原地共享
+func (r *OKVSB) ShiftRow(pivi int, systemk *SystemB, systemi *SystemB) {
+	if systemk.Pos <= pivi && systemk.Row.Bit(pivi-systemk.Pos) == 1 {
+		rowi := new(big.Int).Rsh(systemi.Row, uint(systemk.Pos-systemi.Pos))
+		systemk.Row = systemk.Row.Xor(systemk.Row, rowi)
+		systemk.Value = new(big.Int).Xor(systemk.Value, systemi.Value)
+	}
+}
+
+// Init 用 r.Workers 个 goroutine 计算每一行
+func (r *OKVSB) Init(kvs []KVB) []SystemB {
+	systems := make([]SystemB, r.N)
+	common.ParallelWorkers(r.N, r.Workers, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			r.SetLine(i, &systems[i], &kvs[i])
+		}
+	})
+	return systems
+}
+
+func (r *OKVSB) Encode(kvs []KVB) (*OKVSB, error) {
+	if len(kvs) != r.N {
+		return nil, common.SizeMismatch(r.N, len(kvs))
+	}
+	systems := r.Init(kvs)
+	//fmt.Println("初始化完毕")
+	sort.Slice(systems, func(i, j int) bool {
+		return systems[i].Pos < systems[j].Pos
+	})
+	//fmt.Println("排序完成")
+	//fmt.Println("排序完毕")
+	piv := make([]int, r.N)
+	for i := range piv {
+		piv[i] = -1
+	}
+	for i := 0; i < r.N; i++ {
+		//fmt.Println(i)
+		for j := 0; j < r.W; j++ {
+			if systems[i].Row.Bit(j) == 1 {
+				piv[i] = j + systems[i].Pos
+				for k := i + 1; k < r.N && systems[k].Pos <= piv[i]; k++ {
+					r.ShiftRow(piv[i], &systems[k], &systems[i])
+				}
+				break
+			}
+		}
+		if piv[i] == -1 {
+			return nil, &okvs.SingularError{Row: i, Key: systems[i].Key}
+		}
+	}
+	// 重新编码时旧的 P 不能留在主元位置上
+	for j := range r.P {
+		r.P[j] = new(big.Int)
+	}
+	if r.Rand != nil {
+		if err := r.fillRandom(piv, kvs); err != nil {
+			return nil, err
+		}
+	}
+	for i := r.N - 1; i >= 0; i-- {
+		//reszeroBytes := make([]byte, 4)
+		res := big.NewInt(0)
+		//res = res.ToWidth(32, bitarray.AlignRight)
+		for j := 0; j < r.W; j++ {
+			if systems[i].Row.Bit(j) == 1 {
+				res = res.Xor(res, r.P[systems[i].Pos+j])
+			}
+		}
+		r.P[piv[i]] = res.Xor(res, systems[i].Value)
+	}
+	return r, nil
+}
+
+// EncodeWithRetry 是换 Seed 重试的 Encode，见 common.EncodeWithRetry
+func (r *OKVSB) EncodeWithRetry(kvs []KVB, retries int) (*OKVSB, error) {
+	err := common.EncodeWithRetry(retries, &r.Seed, func() error {
+		_, err := r.Encode(kvs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Decode 只读取 r，多个 goroutine 可以同时解码。Encode 之后 P 中没有 nil；
+// 手工构造的 P 中的 nil 按 0 处理
+func (r *OKVSB) Decode(key []byte) *big.Int {
+	pos := r.hash1(key)
+	row := r.hash2(key)
+	res := big.NewInt(0)
+	for j := pos; j < r.W+pos; j++ {
+		if row.Bit(j-pos) == 1 && r.P[j] != nil {
+			res = res.Xor(res, r.P[j])
+		}
+	}
+	return res
+
+}
+
+// fillRandom 用 L 个随机字节填充非主元位置。宽度不取决于 value，
+// 所以全为 0 的 value 也会被随机掩盖；比 L 长的 value 掩盖不住，返回错误
+func (r *OKVSB) fillRandom(piv []int, kvs []KVB) error {
+	size := r.L
+	if size == 0 {
+		size = DefaultValueSize
+	}
+	for i := range kvs {
+		if l := (kvs[i].Value.BitLen() + 7) / 8; l > size {
+			return common.ValueSize(i, l, size)
+		}
+	}
+	mask := common.PivotMask(len(r.P), piv)
+	buf, err := common.RandomBytes(r.Rand, size*len(r.P))
+	if err != nil {
+		return err
+	}
+	for j := range r.P {
+		if mask[j] {
+			r.P[j] = big.NewInt(0)
+		} else {
+			r.P[j] = new(big.Int).SetBytes(buf[size*j : size*j+size])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package bigint
+
+import (
+	"math/big"
+	mrand "math/rand"
+	"sync"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+const testN = 1000
+
+// encodeB 编码 n 个随机 key 和不超过 bits 位的随机 value，返回 OKVSB 和 kvs
+func encodeB(t *testing.T, rng *mrand.Rand, n, bits int) (*OKVSB, []KVB) {
+	t.Helper()
+	r, err := NewOKVSB(n, okvs.WithSeed([]byte("bigint test seed")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kvs := make([]KVB, n)
+	for i := range kvs {
+		key := make([]byte, 16)
+		rng.Read(key)
+		kvs[i] = KVB{Key: key, Value: new(big.Int).Rand(rng, new(big.Int).Lsh(big.NewInt(1), uint(bits)))}
+	}
+	if _, err := r.Encode(kvs); err != nil {
+		t.Fatal(err)
+	}
+	return r, kvs
+}
+
+// TestEncodeDecode 是 ShiftRow 的回归测试：原来左移会丢掉第 i 行的位，
+// 还会原地改写第 i 行和调用方的 value
+func TestEncodeDecode(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(1))
+	for _, bits := range []int{32, 200} {
+		r, kvs := encodeB(t, rng, testN, bits)
+		want := make([]*big.Int, len(kvs))
+		for i, kv := range kvs {
+			want[i] = new(big.Int).Set(kv.Value)
+		}
+		for i, kv := range kvs {
+			if got := r.Decode(kv.Key); got.Cmp(want[i]) != 0 {
+				t.Fatalf("%d-bit values: Decode = %v, want %v", bits, got, want[i])
+			}
+		}
+		// Encode 不能改写调用方的 value
+		if _, err := r.Encode(kvs); err != nil {
+			t.Fatal(err)
+		}
+		for i, kv := range kvs {
+			if kv.Value.Cmp(want[i]) != 0 {
+				t.Fatalf("%d-bit values: Encode changed kvs[%d].Value to %v, want %v", bits, i, kv.Value, want[i])
+			}
+		}
+	}
+}
+
+// TestDecodeConcurrent 同时从多个 goroutine 解码，用 go test -race 检查 Decode 只读
+func TestDecodeConcurrent(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(2))
+	r, kvs := encodeB(t, rng, testN, 64)
+	const workers = 8
+	bad := make([]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for _, kv := range kvs {
+				if r.Decode(kv.Key).Cmp(kv.Value) != 0 {
+					bad[w]++
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	for w, n := range bad {
+		if n != 0 {
+			t.Fatalf("goroutine %d decoded %d of %d keys wrongly", w, n, len(kvs))
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package bigint
+
+import (
+	"io"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+func init() {
+	okvs.RegisterLoader(okvs.VariantOKVSB, func(h *okvs.Header, payload []byte) (okvs.Store, error) {
+		r, err := okvsbFrom(h, payload)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+// WriteTo writes r as an okvs container. OKVSB values have no fixed width,
+// so every slot takes as many big-endian bytes as the longest one. Rand is
+// not stored.
+func (r *OKVSB) WriteTo(w io.Writer) (int64, error) {
+	width := common.BigWidth(r.P)
+	h := &okvs.Header{
+		Variant:    okvs.VariantOKVSB,
+		Flags:      okvs.HashFlag(r.Hash),
+		ValueWidth: width,
+		N:          r.N,
+		M:          r.M,
+		W:          r.W,
+		R:          r.R,
+		Seed:       r.Seed,
+		Tag:        r.Tag,
+	}
+	return okvs.WriteContainer(w, h, common.PutBigs(r.P, width))
+}
+
+// ReadOKVSB 读取 OKVSB.WriteTo 写的容器
+func ReadOKVSB(rd io.Reader) (*OKVSB, error) {
+	h, payload, err := okvs.ReadContainer(rd)
+	if err != nil {
+		return nil, err
+	}
+	return okvsbFrom(h, payload)
+}
+
+func okvsbFrom(h *okvs.Header, payload []byte) (*OKVSB, error) {
+	if err := h.Expect(okvs.VariantOKVSB, -1, payload); err != nil {
+		return nil, err
+	}
+	r := &OKVSB{
+		N:    h.N,
+		M:    h.M,
+		W:    h.W,
+		R:    h.R,
+		P:    common.Bigs(payload, h.M, h.ValueWidth),
+		Seed: h.Seed,
+		Tag:  h.Tag,
+		Hash: h.HashMode(),
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,51 @@
+package bigint
+
+import (
+	"math/big"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+func init() {
+	okvs.Register("bigint", func(n int, opts ...okvs.Option) (okvs.Store, error) {
+		r, err := NewOKVSB(n, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+type storeOKVSB struct{ r *OKVSB }
+
+// AsStore 把 OKVSB 包装成 Store
+func (r *OKVSB) AsStore() okvs.Store { return storeOKVSB{r} }
+
+func (s storeOKVSB) Encode(pairs []okvs.Pair) error {
+	kvs := make([]KVB, len(pairs))
+	for i := range pairs {
+		if pairs[i].Value == nil {
+			return common.NilValue(i)
+		}
+		kvs[i] = KVB{Key: pairs[i].Key, Value: new(big.Int).Set(pairs[i].Value)}
+	}
+	_, err := s.r.Encode(kvs)
+	return err
+}
+
+func (s storeOKVSB) Decode(key []byte) *big.Int { return s.r.Decode(key) }
+
+func (s storeOKVSB) DecodeBatch(keys [][]byte) []*big.Int { return common.DecodeBatch(keys, s.Decode) }
+
+func (s storeOKVSB) Size() int {
+	size := 0
+	for _, p := range s.r.P {
+		if p != nil {
+			size += (p.BitLen() + 7) / 8
+		}
+	}
+	return size
+}
+
+func (s storeOKVSB) Params() okvs.Params { return okvs.Params{N: s.r.N, M: s.r.M, W: s.r.W, R: s.r.R} }
@@ -0,0 +1,147 @@
+package bpsy23
+
+import (
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/band"
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/tunabay/go-bitarray"
+)
+
+type OKVS struct {
+	N    int //okvs存储的k-v长度
+	M    int //okvs的实际长度
+	W    int //随机块的长度
+	R    int // hashrange
+	P    []*bitarray.BitArray
+	Seed []byte        // hash 种子，为空时等价于不加 key 的 blake2b
+	Tag  []byte        // 域分离标签
+	Rand io.Reader     // 非空时用它随机填充非主元位置，使 OKVS 看起来是均匀随机的
+	Hash okvs.HashMode // 起始位置的 hash 方式，零值是 Hash32，构造函数默认用 Hash64
+	// Workers 是计算 hash 使用的 goroutine 数，0 表示 GOMAXPROCS
+	Workers int
+}
+
+// NewOKVS 根据 n、扩张率和 W 构造 OKVS，M = round(n*e)，R = M - W
+func NewOKVS(n int, opts ...okvs.Option) (*OKVS, error) {
+	c := okvs.NewConfig(opts...)
+	m, err := c.Size(n)
+	if err != nil {
+		return nil, err
+	}
+	r := &OKVS{
+		N:       n,
+		M:       m,
+		W:       c.W,
+		R:       m - c.W,
+		P:       make([]*bitarray.BitArray, m),
+		Seed:    c.Seed,
+		Tag:     c.Tag,
+		Rand:    c.Rand,
+		Hash:    c.Hash,
+		Workers: c.Workers,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate 检查参数是否一致
+func (r *OKVS) Validate() error {
+	if err := common.CheckParams(r.N, r.M, r.W, r.R, len(r.P), r.Seed); err != nil {
+		return err
+	}
+	return r.Hash.Check(r.R)
+}
+
+type KV struct {
+	Key   []byte //key
+	Value uint32 //value
+}
+
+func (r *OKVS) hash1(key []byte) int {
+	return r.Hash.Pos(r.Seed, r.Tag, key, r.R)
+}
+
+func (r *OKVS) hash2(key []byte) *bitarray.BitArray {
+	bandsize := r.W / 8
+	hashBytes := okvs.HashWithSeed(bandsize, r.Seed, r.Tag, key)
+	band := bitarray.NewFromBytes(hashBytes, 0, r.W)
+	//band = band.ToWidth(r.W+pos, bitarray.AlignRight)
+	//band = band.ToWidth(r.M, bitarray.AlignLeft)
+	return band
+}
+
+// Encode 用 word 打包的带状消元求解 P，起始位置按 bit 对齐
+func (r *OKVS) Encode(kvs []KV) (*OKVS, error) {
+	if len(kvs) != r.N {
+		return nil, common.SizeMismatch(r.N, len(kvs))
+	}
+	pos := make([]int, r.N)
+	common.ParallelWorkers(r.N, r.Workers, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			pos[i] = r.hash1(kvs[i].Key)
+		}
+	})
+	s := band.New(r.M, r.W, 1, pos)
+	common.ParallelWorkers(r.N, r.Workers, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			kv := &kvs[s.Idx[i]]
+			band.SetBytes(s.Row(i), okvs.HashWithSeed(r.W/8, r.Seed, r.Tag, kv.Key), r.W)
+			s.Val(i)[0] = uint64(kv.Value)
+		}
+	})
+	piv, fail := s.Eliminate()
+	if fail >= 0 {
+		return nil, &okvs.SingularError{Row: fail, Key: kvs[s.Idx[fail]].Key}
+	}
+	p := make([]uint64, r.M)
+	if r.Rand != nil {
+		if err := common.FillWords(r.Rand, p, 1, 32, piv); err != nil {
+			return nil, err
+		}
+	}
+	s.BackSubstitute(piv, p)
+	var buf [4]byte
+	for j := range p {
+		binary.BigEndian.PutUint32(buf[:], uint32(p[j]))
+		r.P[j] = bitarray.NewFromBytes(buf[:], 0, 32)
+	}
+	return r, nil
+}
+
+// EncodeWithRetry 是换 Seed 重试的 Encode，见 common.EncodeWithRetry
+func (r *OKVS) EncodeWithRetry(kvs []KV, retries int) (*OKVS, error) {
+	err := common.EncodeWithRetry(retries, &r.Seed, func() error {
+		_, err := r.Encode(kvs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Decode 只读取 r，多个 goroutine 可以同时解码。Encode 之后 P 的每一项都是 32 位；
+// 手工构造的 P 中宽度不同的项只在局部补齐，nil 按 0 处理
+func (r *OKVS) Decode(key []byte) *big.Int {
+	pos := r.hash1(key)
+	row := r.hash2(key)
+	res := bitarray.New(0)
+	res = res.ToWidth(32, bitarray.AlignRight)
+	for j := pos; j < r.W+pos; j++ {
+		if row.BitAt(j-pos) == 1 && r.P[j] != nil {
+			p := r.P[j]
+			if p.Len() != 32 {
+				p = p.ToWidth(32, bitarray.AlignRight)
+			}
+			res = res.Xor(p)
+		}
+	}
+	return res.ToInt()
+
+}
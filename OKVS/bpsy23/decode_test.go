@@ -0,0 +1,60 @@
+package bpsy23
+
+import (
+	mrand "math/rand"
+	"sync"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/tunabay/go-bitarray"
+)
+
+// TestDecodeConcurrent 同时从多个 goroutine 解码，用 go test -race 检查 Decode 只读
+func TestDecodeConcurrent(t *testing.T) {
+	const n = 1000
+	rng := mrand.New(mrand.NewSource(1))
+	r, err := NewOKVS(n, okvs.WithSeed([]byte("bpsy23 test seed")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kvs := make([]KV, n)
+	for i := range kvs {
+		key := make([]byte, 16)
+		rng.Read(key)
+		kvs[i] = KV{Key: key, Value: rng.Uint32()}
+	}
+	if _, err := r.Encode(kvs); err != nil {
+		t.Fatal(err)
+	}
+	// 没有随机填充时非主元位置是 0。手工把一个这样的槽位换成 16 位宽的 0，
+	// Decode 只能在局部补齐，不能写回 P
+	j := r.M - 1
+	for r.P[j].ToInt().Sign() != 0 {
+		j--
+	}
+	r.P[j] = bitarray.New(0).ToWidth(16, bitarray.AlignRight)
+
+	const workers = 8
+	bad := make([]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for _, kv := range kvs {
+				if got := r.Decode(kv.Key); !got.IsUint64() || uint32(got.Uint64()) != kv.Value {
+					bad[w]++
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if r.P[j].Len() != 16 {
+		t.Fatalf("Decode rewrote P[%d] to %d bits", j, r.P[j].Len())
+	}
+	for w, k := range bad {
+		if k != 0 {
+			t.Fatalf("goroutine %d decoded %d of %d keys wrongly", w, k, n)
+		}
+	}
+}
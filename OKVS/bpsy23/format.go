@@ -0,0 +1,82 @@
+package bpsy23
+
+import (
+	"encoding/binary"
+	"io"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/tunabay/go-bitarray"
+)
+
+func init() {
+	okvs.RegisterLoader(okvs.VariantBPSY23, func(h *okvs.Header, payload []byte) (okvs.Store, error) {
+		r, err := okvsFrom(h, payload)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+// WriteTo writes r as an okvs container with 4-byte values; nil slots are
+// written as zero. Rand is not stored.
+func (r *OKVS) WriteTo(w io.Writer) (int64, error) {
+	h := &okvs.Header{
+		Variant:    okvs.VariantBPSY23,
+		Flags:      okvs.HashFlag(r.Hash),
+		ValueWidth: 4,
+		N:          r.N,
+		M:          r.M,
+		W:          r.W,
+		R:          r.R,
+		Seed:       r.Seed,
+		Tag:        r.Tag,
+	}
+	p := make([]uint32, len(r.P))
+	for j, v := range r.P {
+		if v == nil {
+			continue
+		}
+		if v.Len() != 32 {
+			v = v.ToWidth(32, bitarray.AlignRight)
+		}
+		b, _ := v.Bytes()
+		p[j] = binary.BigEndian.Uint32(b)
+	}
+	return okvs.WriteContainer(w, h, common.PutUint32s(p))
+}
+
+// ReadOKVS 读取 OKVS.WriteTo 写的容器，P 的每一项都是 32 位
+func ReadOKVS(rd io.Reader) (*OKVS, error) {
+	h, payload, err := okvs.ReadContainer(rd)
+	if err != nil {
+		return nil, err
+	}
+	return okvsFrom(h, payload)
+}
+
+func okvsFrom(h *okvs.Header, payload []byte) (*OKVS, error) {
+	if err := h.Expect(okvs.VariantBPSY23, 4, payload); err != nil {
+		return nil, err
+	}
+	r := &OKVS{
+		N:    h.N,
+		M:    h.M,
+		W:    h.W,
+		R:    h.R,
+		P:    make([]*bitarray.BitArray, h.M),
+		Seed: h.Seed,
+		Tag:  h.Tag,
+		Hash: h.HashMode(),
+	}
+	var buf [4]byte
+	for j, v := range common.Uint32s(payload) {
+		binary.BigEndian.PutUint32(buf[:], v)
+		r.P[j] = bitarray.NewFromBytes(buf[:], 0, 32)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,44 @@
+package bpsy23
+
+import (
+	"math/big"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+func init() {
+	okvs.Register("bpsy23", func(n int, opts ...okvs.Option) (okvs.Store, error) {
+		r, err := NewOKVS(n, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+type storeOKVS struct{ r *OKVS }
+
+// AsStore 把 OKVS 包装成 Store
+func (r *OKVS) AsStore() okvs.Store { return storeOKVS{r} }
+
+func (s storeOKVS) Encode(pairs []okvs.Pair) error {
+	kvs := make([]KV, len(pairs))
+	for i := range pairs {
+		v, err := common.ToUint32(pairs[i].Value)
+		if err != nil {
+			return err
+		}
+		kvs[i] = KV{Key: pairs[i].Key, Value: v}
+	}
+	_, err := s.r.Encode(kvs)
+	return err
+}
+
+func (s storeOKVS) Decode(key []byte) *big.Int { return s.r.Decode(key) }
+
+func (s storeOKVS) DecodeBatch(keys [][]byte) []*big.Int { return common.DecodeBatch(keys, s.Decode) }
+
+func (s storeOKVS) Size() int { return s.r.M * 4 }
+
+func (s storeOKVS) Params() okvs.Params { return okvs.Params{N: s.r.N, M: s.r.M, W: s.r.W, R: s.r.R} }
@@ -0,0 +1,149 @@
+package buffer
+
+import (
+	"io"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/band"
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/tunabay/go-bitarray"
+)
+
+type SystemBF struct {
+	Pos   int
+	Row   *bitarray.Buffer
+	Value *bitarray.Buffer
+	Key   []byte
+}
+
+type OKVSBF struct {
+	N    int //okvs存储的k-v长度
+	M    int //okvs的实际长度
+	W    int //随机块的长度
+	P    []*bitarray.Buffer
+	Seed []byte        // hash 种子，为空时等价于不加 key 的 blake2b
+	Tag  []byte        // 域分离标签
+	Rand io.Reader     // 非空时用它随机填充非主元位置，使 OKVS 看起来是均匀随机的
+	Hash okvs.HashMode // 起始位置的 hash 方式，零值是 Hash32，构造函数默认用 Hash64
+}
+
+// NewOKVSBF 根据 n、扩张率和 W 构造 OKVSBF，M = round(n*e)，R = M - W
+func NewOKVSBF(n int, opts ...okvs.Option) (*OKVSBF, error) {
+	c := okvs.NewConfig(opts...)
+	m, err := c.Size(n)
+	if err != nil {
+		return nil, err
+	}
+	r := &OKVSBF{
+		N:    n,
+		M:    m,
+		W:    c.W,
+		P:    make([]*bitarray.Buffer, m),
+		Seed: c.Seed,
+		Tag:  c.Tag,
+		Rand: c.Rand,
+		Hash: c.Hash,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate 检查参数是否一致
+func (r *OKVSBF) Validate() error {
+	if err := common.CheckParams(r.N, r.M, r.W, r.M-r.W, len(r.P), r.Seed); err != nil {
+		return err
+	}
+	return r.Hash.Check(r.M - r.W)
+}
+
+type KVBF struct {
+	Key   []byte //key
+	Value uint32 //value
+}
+
+func (r *OKVSBF) hash1(key []byte) int {
+	return r.Hash.Pos(r.Seed, r.Tag, key, r.M-r.W)
+}
+
+func (r *OKVSBF) hash2(key []byte) *bitarray.Buffer {
+	bandsize := int(r.W) / 8
+	hashBytes := okvs.HashWithSeed(bandsize, r.Seed, r.Tag, key)
+	band := bitarray.NewBufferFromByteSlice(hashBytes)
+	return band
+}
+
+func (r *OKVSBF) Init(kvs []KVBF) []SystemBF {
+	systems := make([]SystemBF, r.N)
+	for i := 0; i < r.N; i++ {
+		systems[i].Pos = r.hash1(kvs[i].Key)
+		systems[i].Row = r.hash2(kvs[i].Key)
+		systems[i].Value = bitarray.NewBuffer(32)
+		systems[i].Value.PutUint32(kvs[i].Value)
+		systems[i].Key = kvs[i].Key
+	}
+	return systems
+}
+
+// Encode 用 word 打包的带状消元求解 P，起始位置按 bit 对齐
+func (r *OKVSBF) Encode(kvs []KVBF) (*OKVSBF, error) {
+	if len(kvs) != r.N {
+		return nil, common.SizeMismatch(r.N, len(kvs))
+	}
+	pos := make([]int, r.N)
+	common.ParallelFor(r.N, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			pos[i] = r.hash1(kvs[i].Key)
+		}
+	})
+	s := band.New(r.M, r.W, 1, pos)
+	common.ParallelFor(r.N, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			kv := &kvs[s.Idx[i]]
+			band.SetBytes(s.Row(i), okvs.HashWithSeed(r.W/8, r.Seed, r.Tag, kv.Key), r.W)
+			s.Val(i)[0] = uint64(kv.Value)
+		}
+	})
+	piv, fail := s.Eliminate()
+	if fail >= 0 {
+		return nil, &okvs.SingularError{Row: fail, Key: kvs[s.Idx[fail]].Key}
+	}
+	p := make([]uint64, r.M)
+	if r.Rand != nil {
+		if err := common.FillWords(r.Rand, p, 1, 32, piv); err != nil {
+			return nil, err
+		}
+	}
+	s.BackSubstitute(piv, p)
+	for j := range p {
+		r.P[j] = bitarray.NewBuffer(32)
+		r.P[j].PutUint32(uint32(p[j]))
+	}
+	return r, nil
+}
+
+// EncodeWithRetry 是换 Seed 重试的 Encode，见 common.EncodeWithRetry
+func (r *OKVSBF) EncodeWithRetry(kvs []KVBF, retries int) (*OKVSBF, error) {
+	err := common.EncodeWithRetry(retries, &r.Seed, func() error {
+		_, err := r.Encode(kvs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *OKVSBF) Decode(key []byte) uint32 {
+	pos := r.hash1(key)
+	row := r.hash2(key)
+	res := bitarray.NewBuffer(32)
+	for j := pos; j < r.W+pos; j++ {
+		if row.BitAt(j-pos) == 1 {
+			res.XorAt(0, r.P[j])
+		}
+	}
+	return res.Uint32()
+
+}
@@ -0,0 +1,77 @@
+package buffer
+
+import (
+	"io"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/tunabay/go-bitarray"
+)
+
+func init() {
+	okvs.RegisterLoader(okvs.VariantOKVSBF, func(h *okvs.Header, payload []byte) (okvs.Store, error) {
+		r, err := okvsbfFrom(h, payload)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+// WriteTo writes r as an okvs container with 4-byte values; nil slots are
+// written as zero. Rand is not stored.
+func (r *OKVSBF) WriteTo(w io.Writer) (int64, error) {
+	h := &okvs.Header{
+		Variant:    okvs.VariantOKVSBF,
+		Flags:      okvs.HashFlag(r.Hash),
+		ValueWidth: 4,
+		N:          r.N,
+		M:          r.M,
+		W:          r.W,
+		R:          r.M - r.W,
+		Seed:       r.Seed,
+		Tag:        r.Tag,
+	}
+	p := make([]uint32, len(r.P))
+	for j, v := range r.P {
+		if v != nil {
+			p[j] = v.Uint32()
+		}
+	}
+	return okvs.WriteContainer(w, h, common.PutUint32s(p))
+}
+
+// ReadOKVSBF 读取 OKVSBF.WriteTo 写的容器
+func ReadOKVSBF(rd io.Reader) (*OKVSBF, error) {
+	h, payload, err := okvs.ReadContainer(rd)
+	if err != nil {
+		return nil, err
+	}
+	return okvsbfFrom(h, payload)
+}
+
+func okvsbfFrom(h *okvs.Header, payload []byte) (*OKVSBF, error) {
+	if err := h.Expect(okvs.VariantOKVSBF, 4, payload); err != nil {
+		return nil, err
+	}
+	if h.R != h.M-h.W {
+		return nil, common.ParamError("R = %d, want M - W = %d", h.R, h.M-h.W)
+	}
+	r := &OKVSBF{
+		N:    h.N,
+		M:    h.M,
+		W:    h.W,
+		P:    make([]*bitarray.Buffer, h.M),
+		Seed: h.Seed,
+		Tag:  h.Tag,
+		Hash: h.HashMode(),
+	}
+	for j, v := range common.Uint32s(payload) {
+		r.P[j] = bitarray.NewBuffer(32)
+		r.P[j].PutUint32(v)
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,20 @@
+package buffer
+
+import (
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/OurOKVS/OKVS/linear"
+)
+
+// Matrix 返回 keys 对应的解码矩阵，P 按 32 位 value 转成 []uint32 后
+// linear.Mul(a, p)[i] 等于 Decode(keys[i])
+func (r *OKVSBF) Matrix(keys [][]byte) *linear.F2 {
+	a := &linear.F2{N: len(keys), M: r.M, W: r.W, Pos: make([]int, len(keys)), Rows: make([][]byte, len(keys))}
+	common.ParallelFor(len(keys), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			a.Pos[i] = r.hash1(keys[i])
+			a.Rows[i] = okvs.HashWithSeed(r.W/8, r.Seed, r.Tag, keys[i])
+		}
+	})
+	return a
+}
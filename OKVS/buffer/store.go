@@ -0,0 +1,48 @@
+package buffer
+
+import (
+	"math/big"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+func init() {
+	okvs.Register("buffer", func(n int, opts ...okvs.Option) (okvs.Store, error) {
+		r, err := NewOKVSBF(n, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+type storeOKVSBF struct{ r *OKVSBF }
+
+// AsStore 把 OKVSBF 包装成 Store
+func (r *OKVSBF) AsStore() okvs.Store { return storeOKVSBF{r} }
+
+func (s storeOKVSBF) Encode(pairs []okvs.Pair) error {
+	kvs := make([]KVBF, len(pairs))
+	for i := range pairs {
+		v, err := common.ToUint32(pairs[i].Value)
+		if err != nil {
+			return err
+		}
+		kvs[i] = KVBF{Key: pairs[i].Key, Value: v}
+	}
+	_, err := s.r.Encode(kvs)
+	return err
+}
+
+func (s storeOKVSBF) Decode(key []byte) *big.Int {
+	return new(big.Int).SetUint64(uint64(s.r.Decode(key)))
+}
+
+func (s storeOKVSBF) DecodeBatch(keys [][]byte) []*big.Int { return common.DecodeBatch(keys, s.Decode) }
+
+func (s storeOKVSBF) Size() int { return s.r.M * 4 }
+
+func (s storeOKVSBF) Params() okvs.Params {
+	return okvs.Params{N: s.r.N, M: s.r.M, W: s.r.W, R: s.r.M - s.r.W}
+}
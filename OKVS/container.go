@@ -0,0 +1,402 @@
+package okvs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Magic 是容器文件开头的 4 个字节
+const Magic = "OKVS"
+
+// FormatVersion 是 WriteContainer 写入、ReadContainer 接受的唯一版本
+const FormatVersion = 1
+
+// PayloadAlign 是 payload 在容器里的对齐字节数，映射到内存后可以直接当作 P 使用
+const PayloadAlign = 8
+
+// DigestSize 是容器末尾 BLAKE2b-256 摘要的字节数
+const DigestSize = 32
+
+var (
+	// ErrFormat 表示输入不是一个合法的容器
+	ErrFormat = errors.New("okvs: malformed container")
+	// ErrVersion 表示容器的版本不被支持
+	ErrVersion = errors.New("okvs: unsupported container version")
+	// ErrChecksum 表示容器的摘要和内容不一致
+	ErrChecksum = errors.New("okvs: container checksum mismatch")
+)
+
+// Variant 标识容器里保存的是哪一种 OKVS
+type Variant uint8
+
+const (
+	VariantOKVSBK  Variant = 1 + iota // ourf2.OKVSBK
+	VariantOKVSBKW                    // ourf2.OKVSBKW
+	VariantOKVSECC                    // ecdlp.OKVSECC
+	VariantBPSY23                     // bpsy23.OKVS
+	VariantOKVSB                      // bigint.OKVSB
+	VariantOKVSBF                     // buffer.OKVSBF
+	VariantOKVSFp                     // fp.OKVSFp
+)
+
+var variantNames = map[Variant]string{
+	VariantOKVSBK:  "OKVSBK",
+	VariantOKVSBKW: "OKVSBKW",
+	VariantOKVSECC: "OKVSECC",
+	VariantBPSY23:  "OKVS",
+	VariantOKVSB:   "OKVSB",
+	VariantOKVSBF:  "OKVSBF",
+	VariantOKVSFp:  "OKVSFp",
+}
+
+func (v Variant) String() string {
+	if s, ok := variantNames[v]; ok {
+		return s
+	}
+	return fmt.Sprintf("Variant(%d)", uint8(v))
+}
+
+// Header 中 Flags 的各个位
+const (
+	FlagBitPos   = 1 << iota // 起始位置精确到 bit
+	FlagRandCoef             // 带内系数是随机域元素
+	FlagHash32               // 起始位置用 Hash32 计算
+)
+
+// Header describes the structure stored in a container. ValueWidth is the
+// number of bytes each of the M slots of P takes in the payload.
+type Header struct {
+	Version    int
+	Variant    Variant
+	Flags      uint8
+	ValueWidth int
+	N, M, W, R int
+	Seed       []byte
+	Tag        []byte
+	Modulus    *big.Int // 只有素数域的变体有
+}
+
+// WriteContainer writes h and payload as
+//
+//	magic "OKVS" | version u16 | variant u8 | flags u8 | value width u32 |
+//	N u64 | M u64 | W u32 | R u64 | seed, tag, modulus (u16 length + bytes) |
+//	payload length u64 | zero padding to PayloadAlign | payload |
+//	BLAKE2b-256 of everything before it
+//
+// with all integers little-endian and the modulus big-endian. h.Version is
+// ignored; FormatVersion is written.
+func WriteContainer(w io.Writer, h *Header, payload []byte) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString(Magic)
+	le := binary.LittleEndian
+	buf.Write(le.AppendUint16(nil, FormatVersion))
+	buf.WriteByte(byte(h.Variant))
+	buf.WriteByte(h.Flags)
+	buf.Write(le.AppendUint32(nil, uint32(h.ValueWidth)))
+	buf.Write(le.AppendUint64(nil, uint64(h.N)))
+	buf.Write(le.AppendUint64(nil, uint64(h.M)))
+	buf.Write(le.AppendUint32(nil, uint32(h.W)))
+	buf.Write(le.AppendUint64(nil, uint64(h.R)))
+	var mod []byte
+	if h.Modulus != nil {
+		mod = h.Modulus.Bytes()
+	}
+	for _, b := range [][]byte{h.Seed, h.Tag, mod} {
+		if len(b) > 0xffff {
+			return 0, fmt.Errorf("%w: header field of %d bytes", ErrFormat, len(b))
+		}
+		buf.Write(le.AppendUint16(nil, uint16(len(b))))
+		buf.Write(b)
+	}
+	buf.Write(le.AppendUint64(nil, uint64(len(payload))))
+	buf.Write(make([]byte, padding(buf.Len())))
+
+	d, _ := blake2b.New256(nil)
+	d.Write(buf.Bytes())
+	d.Write(payload)
+	var n int64
+	for _, b := range [][]byte{buf.Bytes(), payload, d.Sum(nil)} {
+		k, err := w.Write(b)
+		n += int64(k)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// ReadContainer reads a container written by WriteContainer and checks its
+// magic, version and digest. Before allocating the payload it checks the
+// header fields against each other (see Header.Check) and, when r is a
+// regular file or has a Len method, against the bytes that are left, so a
+// truncated or forged header fails with ErrFormat instead of allocating what
+// it claims.
+func ReadContainer(r io.Reader) (*Header, []byte, error) {
+	left := remaining(r)
+	d, _ := blake2b.New256(nil)
+	cr := &countingReader{r: io.TeeReader(r, d)}
+	h, plen, err := readHeader(cr)
+	if err != nil {
+		return nil, nil, err
+	}
+	// 先减去摘要再比较，plen 接近 2^64 时相加会回绕
+	if left >= 0 && (left-cr.n < DigestSize || plen > uint64(left-cr.n)-DigestSize) {
+		return nil, nil, fmt.Errorf("%w: payload of %d bytes and digest do not fit in the %d bytes left", ErrFormat, plen, left-cr.n)
+	}
+	// 长度未知时分块读入，截断的输入不会先分配头里声称的大小
+	var payload bytes.Buffer
+	if left >= 0 {
+		payload.Grow(int(plen))
+	}
+	if _, err := io.CopyN(&payload, cr, int64(plen)); err != nil {
+		return nil, nil, fmt.Errorf("%w: payload: %v", ErrFormat, err)
+	}
+	sum := d.Sum(nil)
+	var got [DigestSize]byte
+	if _, err := io.ReadFull(r, got[:]); err != nil {
+		return nil, nil, fmt.Errorf("%w: digest: %v", ErrFormat, err)
+	}
+	if !bytes.Equal(sum, got[:]) {
+		return nil, nil, ErrChecksum
+	}
+	return h, payload.Bytes(), nil
+}
+
+// ParseContainer parses a whole container held in b, typically a
+// memory-mapped file, and returns the payload as a subslice of b. It checks
+// the structure like ReadContainer and that nothing follows the digest, but
+// not the digest itself: hashing a multi-gigabyte table would defeat mapping
+// it. Call CheckDigest for that.
+func ParseContainer(b []byte) (*Header, []byte, error) {
+	br := bytes.NewReader(b)
+	h, plen, err := readHeader(br)
+	if err != nil {
+		return nil, nil, err
+	}
+	// plen 已经被 Check 限制在 MaxInt 以内，这里先减去摘要再比较，避免相加回绕
+	if left := uint64(br.Len()); left < DigestSize || plen != left-DigestSize {
+		return nil, nil, fmt.Errorf("%w: payload of %d bytes and digest, but %d bytes left", ErrFormat, plen, br.Len())
+	}
+	off := len(b) - br.Len()
+	return h, b[off : off+int(plen) : off+int(plen)], nil
+}
+
+// CheckDigest checks the digest at the end of the container b.
+func CheckDigest(b []byte) error {
+	if len(b) < DigestSize {
+		return fmt.Errorf("%w: %d bytes", ErrFormat, len(b))
+	}
+	n := len(b) - DigestSize
+	sum := blake2b.Sum256(b[:n])
+	if !bytes.Equal(sum[:], b[n:]) {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// readHeader 读取 payload 之前的所有字段和对齐填充，并用 Check 检查
+func readHeader(r io.Reader) (*Header, uint64, error) {
+	var fixed [4 + 2 + 1 + 1 + 4 + 8 + 8 + 4 + 8]byte
+	if _, err := io.ReadFull(r, fixed[:]); err != nil {
+		return nil, 0, fmt.Errorf("%w: header: %v", ErrFormat, err)
+	}
+	if string(fixed[:4]) != Magic {
+		return nil, 0, fmt.Errorf("%w: bad magic %q", ErrFormat, fixed[:4])
+	}
+	le := binary.LittleEndian
+	h := &Header{
+		Version:    int(le.Uint16(fixed[4:])),
+		Variant:    Variant(fixed[6]),
+		Flags:      fixed[7],
+		ValueWidth: int(le.Uint32(fixed[8:])),
+		N:          toInt(le.Uint64(fixed[12:])),
+		M:          toInt(le.Uint64(fixed[20:])),
+		W:          int(le.Uint32(fixed[28:])),
+		R:          toInt(le.Uint64(fixed[32:])),
+	}
+	if h.Version != FormatVersion {
+		return nil, 0, fmt.Errorf("%w: %d", ErrVersion, h.Version)
+	}
+	n := len(fixed)
+	var fields [3][]byte
+	for i := range fields {
+		var l [2]byte
+		if _, err := io.ReadFull(r, l[:]); err != nil {
+			return nil, 0, fmt.Errorf("%w: header: %v", ErrFormat, err)
+		}
+		fields[i] = make([]byte, le.Uint16(l[:]))
+		if _, err := io.ReadFull(r, fields[i]); err != nil {
+			return nil, 0, fmt.Errorf("%w: header: %v", ErrFormat, err)
+		}
+		n += 2 + len(fields[i])
+	}
+	h.Seed, h.Tag = fields[0], fields[1]
+	if len(fields[2]) > 0 {
+		h.Modulus = new(big.Int).SetBytes(fields[2])
+	}
+	var l [8 + PayloadAlign]byte
+	if _, err := io.ReadFull(r, l[:8]); err != nil {
+		return nil, 0, fmt.Errorf("%w: header: %v", ErrFormat, err)
+	}
+	plen := le.Uint64(l[:8])
+	pad := l[8 : 8+padding(n+8)]
+	if _, err := io.ReadFull(r, pad); err != nil {
+		return nil, 0, fmt.Errorf("%w: header: %v", ErrFormat, err)
+	}
+	for _, c := range pad {
+		if c != 0 {
+			return nil, 0, fmt.Errorf("%w: nonzero padding", ErrFormat)
+		}
+	}
+	if err := h.Check(plen); err != nil {
+		return nil, 0, err
+	}
+	return h, plen, nil
+}
+
+// padding 返回 n 个字节之后补到 PayloadAlign 的倍数要填的字节数
+func padding(n int) int {
+	return -n & (PayloadAlign - 1)
+}
+
+// Check validates the header fields against each other and against the
+// payload length plen: N, M, W and R are positive, W is a multiple of 8,
+// N <= M, R + W <= M, the seed fits blake2b and the payload is exactly M
+// slots of ValueWidth bytes, ValueWidth being positive so that M is bounded
+// by what was actually read. plen itself must fit in an int.
+func (h *Header) Check(plen uint64) error {
+	switch {
+	case plen > math.MaxInt:
+		return fmt.Errorf("%w: payload has %d bytes", ErrFormat, plen)
+	case h.N <= 0 || h.M <= 0 || h.W <= 0 || h.R <= 0:
+		return fmt.Errorf("%w: N = %d, M = %d, W = %d, R = %d must be positive", ErrFormat, h.N, h.M, h.W, h.R)
+	case h.W%8 != 0:
+		return fmt.Errorf("%w: W = %d is not a multiple of 8", ErrFormat, h.W)
+	case h.N > h.M:
+		return fmt.Errorf("%w: N = %d exceeds M = %d", ErrFormat, h.N, h.M)
+	case h.W > h.M || h.R > h.M-h.W:
+		return fmt.Errorf("%w: R + W = %d exceeds M = %d", ErrFormat, h.R+h.W, h.M)
+	case len(h.Seed) > blake2b.Size:
+		return fmt.Errorf("%w: seed has %d bytes", ErrFormat, len(h.Seed))
+	case h.ValueWidth <= 0 || plen%uint64(h.ValueWidth) != 0 || plen/uint64(h.ValueWidth) != uint64(h.M):
+		return fmt.Errorf("%w: payload has %d bytes, want M = %d slots of %d bytes", ErrFormat, plen, h.M, h.ValueWidth)
+	}
+	return nil
+}
+
+// HashFlag 返回 WriteContainer 为 m 写入的 Flags 位
+func HashFlag(m HashMode) uint8 {
+	if m == Hash32 {
+		return FlagHash32
+	}
+	return 0
+}
+
+// HashMode returns the hash mode the stored OKVS was encoded with.
+func (h *Header) HashMode() HashMode {
+	if h.Flags&FlagHash32 != 0 {
+		return Hash32
+	}
+	return Hash64
+}
+
+// toInt 把文件里的 u64 转成 int，放不下时返回 -1，交给 Check 报错
+func toInt(x uint64) int {
+	if x > math.MaxInt {
+		return -1
+	}
+	return int(x)
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	k, err := c.r.Read(p)
+	c.n += int64(k)
+	return k, err
+}
+
+// remaining 返回 r 中还剩的字节数，不知道时返回 -1
+func remaining(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		st, err := v.Stat()
+		if err != nil || !st.Mode().IsRegular() {
+			return -1
+		}
+		off, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return st.Size() - off
+	}
+	return -1
+}
+
+// Loader 从容器的头和 payload 构造一个 Store
+type Loader func(h *Header, payload []byte) (Store, error)
+
+var (
+	loadersMu sync.RWMutex
+	loaders   = make(map[Variant]Loader)
+)
+
+// RegisterLoader makes Load understand containers of variant v. Backend
+// packages call it from init next to Register.
+func RegisterLoader(v Variant, f Loader) {
+	loadersMu.Lock()
+	defer loadersMu.Unlock()
+	if f == nil {
+		panic("okvs: RegisterLoader loader is nil")
+	}
+	if _, dup := loaders[v]; dup {
+		panic("okvs: RegisterLoader called twice for " + v.String())
+	}
+	loaders[v] = f
+}
+
+// Load reads any container whose variant has been registered and returns it
+// as a Store.
+func Load(r io.Reader) (Store, error) {
+	h, payload, err := ReadContainer(r)
+	if err != nil {
+		return nil, err
+	}
+	loadersMu.RLock()
+	f, ok := loaders[h.Variant]
+	loadersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w for %v (forgotten import?)", ErrUnknownBackend, h.Variant)
+	}
+	return f(h, payload)
+}
+
+// Expect checks that h holds variant v and that the payload has exactly M
+// slots of ValueWidth bytes. A width of -1 accepts any ValueWidth, for the
+// variants whose value width depends on the data.
+func (h *Header) Expect(v Variant, width int, payload []byte) error {
+	if h.Variant != v {
+		return fmt.Errorf("%w: container holds %v, want %v", ErrFormat, h.Variant, v)
+	}
+	if width >= 0 && h.ValueWidth != width {
+		return fmt.Errorf("%w: value width %d, want %d", ErrFormat, h.ValueWidth, width)
+	}
+	if h.M < 0 || h.ValueWidth < 0 || len(payload) != h.M*h.ValueWidth {
+		return fmt.Errorf("%w: payload has %d bytes for M = %d slots of %d bytes", ErrFormat, len(payload), h.M, h.ValueWidth)
+	}
+	return nil
+}
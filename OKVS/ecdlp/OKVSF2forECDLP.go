@@ -0,0 +1,357 @@
+package ecdlp
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/band"
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/OurOKVS/OKVS/internal/kernel"
+)
+
+// 定义System结构体
+type SystemECC struct {
+	Pos   int
+	BPos  int
+	Row   []byte
+	Value uint32
+	Key   []byte
+}
+
+type OKVSECC struct {
+	N    int //okvs存储的k-v长度
+	M    int //okvs的实际长度
+	W    int //随机块的长度
+	B    int //桶的个度
+	R    int // hashrange
+	P    []uint32
+	Seed []byte    // hash 种子，Hash32 下 Seed 和 Tag 都为空时 hash1 和 hash2 直接用 key 的字节
+	Tag  []byte    // 域分离标签
+	Rand io.Reader // 非空时用它随机填充非主元位置，使 OKVS 看起来是均匀随机的
+	// Workers 是 DecodeBatch 使用的 goroutine 数，0 表示 GOMAXPROCS
+	Workers int
+	// Hash 是 hash 方式，零值是 Hash32，和旧代码写的表一致；构造函数默认用 Hash64
+	Hash okvs.HashMode
+}
+
+// NewOKVSECC 根据 n、扩张率和 W 构造 OKVSECC，M = round(n*e)，R = M - W
+func NewOKVSECC(n int, opts ...okvs.Option) (*OKVSECC, error) {
+	c := okvs.NewConfig(opts...)
+	m, err := c.Size(n)
+	if err != nil {
+		return nil, err
+	}
+	r := &OKVSECC{
+		N:       n,
+		M:       m,
+		W:       c.W,
+		B:       c.W / 8,
+		R:       m - c.W,
+		P:       make([]uint32, m),
+		Seed:    c.Seed,
+		Tag:     c.Tag,
+		Rand:    c.Rand,
+		Workers: c.Workers,
+		Hash:    c.Hash,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate 检查参数是否一致
+func (r *OKVSECC) Validate() error {
+	if err := common.CheckParams(r.N, r.M, r.W, r.R, len(r.P), r.Seed); err != nil {
+		return err
+	}
+	if r.B != r.W/8 {
+		return common.ParamError("B = %d, want W/8 = %d", r.B, r.W/8)
+	}
+	return r.Hash.Check(r.R)
+}
+
+// SerializeOKVSECC 把 OKVSECC 以旧的无头格式写到文件，不保存 Seed 和 Tag。
+// 参数超过 int32 时返回错误。
+//
+// Deprecated: 使用 OKVSECC.WriteTo，它写入带版本、种子和摘要的容器。
+func SerializeOKVSECC(filename string, data OKVSECC) error {
+	if err := common.CheckLegacy(data.N, data.M, data.W, data.R); err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// 写入基本数据
+	err = binary.Write(file, binary.LittleEndian, int32(data.N))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, int32(data.M))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, int32(data.W))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, int32(data.B))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, int32(data.R))
+	if err != nil {
+		return err
+	}
+
+	// 写入 P 数组长度和内容
+	err = binary.Write(file, binary.LittleEndian, int32(len(data.P)))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, data.P)
+	if err != nil {
+		return err
+	}
+
+	// Hash64 用 P 之后的一个标志字节表示，旧文件没有这个字节
+	if f := common.LegacyFlags(false, data.Hash); f != 0 {
+		_, err = file.Write([]byte{f})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeserializeOKVSECC 读取 SerializeOKVSECC 写的旧格式文件。
+//
+// Deprecated: 使用 ReadOKVSECC 读取 WriteTo 写的容器。
+func DeserializeOKVSECC(filename string) (OKVSECC, error) {
+	f, err := common.ReadLegacy(filename, 1)
+	if err != nil {
+		return OKVSECC{}, err
+	}
+	r, err := okvseccFromLegacy(f)
+	if err != nil {
+		return OKVSECC{}, err
+	}
+	return *r, nil
+}
+
+func okvseccFromLegacy(f *common.LegacyFile) (*OKVSECC, error) {
+	data := &OKVSECC{N: f.N, M: f.M, W: f.W, B: f.B, R: f.R, P: f.P, Hash: okvs.Hash32}
+	if len(f.Tail) == 1 {
+		if f.Tail[0] != common.LegacyHash64 {
+			return nil, common.FormatError("legacy flags are %#x", f.Tail[0])
+		}
+		data.Hash = okvs.Hash64
+	}
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+type KVECC struct {
+	Key   []byte //key
+	Value uint32 //value
+}
+
+func (r *OKVSECC) hash1(key []byte) int {
+	if r.Hash != okvs.Hash32 {
+		return r.Hash.Pos(r.Seed, r.Tag, key, r.R)
+	}
+	hashkey := prefix(key, 4)
+	if len(r.Seed) > 0 || len(r.Tag) > 0 {
+		hashkey = okvs.HashWithSeed(4, r.Seed, r.Tag, key)
+	}
+	//fmt.Println(r.R)
+	hashkeyint := int(binary.BigEndian.Uint32(hashkey)) % r.R
+	return hashkeyint
+}
+
+// hash2 和 hash1 一样只在 Hash32 且没有 Seed 和 Tag 时直接用 key 的字节，
+// 其余情况都用 blake2b
+func (r *OKVSECC) hash2(key []byte) []byte {
+	bandsize := r.W / 8
+	if r.Hash != okvs.Hash32 || len(r.Seed) > 0 || len(r.Tag) > 0 {
+		return okvs.HashWithSeed(bandsize, r.Seed, r.Tag, key)
+	}
+	hashBytes := prefix(key, bandsize)
+	return hashBytes
+}
+
+// prefix 返回 key 的前 n 个字节，key 不够长时在后面补 0
+func prefix(key []byte, n int) []byte {
+	if len(key) >= n {
+		return key[:n]
+	}
+	b := make([]byte, n)
+	copy(b, key)
+	return b
+}
+
+func (r *OKVSECC) SetLine(i int, system *SystemECC, kv *KVECC) {
+	system.Pos = r.hash1(kv.Key)
+	//fmt.Println(system.Pos)
+	system.BPos = int(system.Pos / 8)
+	system.Pos = system.BPos * 8
+	// hash2 返回的是 key 的子切片，消元会原地修改 Row，所以这里要复制一份
+	system.Row = append([]byte(nil), r.hash2(kv.Key)...)
+	system.Value = kv.Value
+	system.Key = kv.Key
+}
+
+func (r *OKVSECC) Init(kvs []KVECC) []SystemECC {
+	systems := make([]SystemECC, r.N)
+	for i := 1; i < r.N; i++ {
+		r.SetLine(i, &systems[i], &kvs[i])
+	}
+	//fmt.Println("system 5", systems[5].Row)
+	//fmt.Println(systems[5].Row)
+	return systems
+}
+
+// Encode 用 word 打包的带状消元求解 P，起始位置取整到 8 的倍数。
+// 和原来一样，kvs[0] 不参与编码
+func (r *OKVSECC) Encode(kvs []KVECC) (*OKVSECC, error) {
+	if len(kvs) != r.N {
+		return nil, common.SizeMismatch(r.N, len(kvs))
+	}
+	kvs = kvs[1:]
+	pos := make([]int, len(kvs))
+	common.ParallelFor(len(kvs), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			pos[i] = r.hash1(kvs[i].Key) / 8 * 8
+		}
+	})
+	s := band.New(r.M, r.W, 1, pos)
+	common.ParallelFor(len(kvs), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			kv := &kvs[s.Idx[i]]
+			band.SetBytes(s.Row(i), r.hash2(kv.Key), r.W)
+			s.Val(i)[0] = uint64(kv.Value)
+		}
+	})
+	piv, fail := s.Eliminate()
+	if fail >= 0 {
+		return nil, &okvs.SingularError{Row: fail + 1, Key: kvs[s.Idx[fail]].Key}
+	}
+	p := make([]uint64, r.M)
+	if r.Rand != nil {
+		if err := common.FillWords(r.Rand, p, 1, 32, piv); err != nil {
+			return nil, err
+		}
+	}
+	s.BackSubstitute(piv, p)
+	for j := range r.P {
+		r.P[j] = uint32(p[j])
+	}
+	return r, nil
+}
+
+// EncodeWithRetry 是换 Seed 重试的 Encode，见 common.EncodeWithRetry
+func (r *OKVSECC) EncodeWithRetry(kvs []KVECC, retries int) (*OKVSECC, error) {
+	err := common.EncodeWithRetry(retries, &r.Seed, func() error {
+		_, err := r.Encode(kvs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *OKVSECC) ShiftRowBK(wg *sync.WaitGroup, i int, iend int, pivi int, systems *[]SystemECC) {
+	defer wg.Done()
+	for k := i; k < iend; k++ {
+		if (*systems)[k].Pos <= pivi {
+			posk := pivi - (*systems)[k].Pos
+			if kernel.GetBit((*systems)[k].Row[int(posk/8)], posk%8) {
+				shiftnum := (*systems)[k].BPos - (*systems)[i].BPos
+				kernel.XorShift((*systems)[k].Row, (*systems)[i].Row, (*systems)[k].Row, r.B-shiftnum, shiftnum)
+				(*systems)[k].Value = (*systems)[k].Value ^ (*systems)[i].Value
+			}
+		}
+	}
+}
+
+func (r *OKVSECC) Decode(key []byte) uint32 {
+	if len(key) == 0 {
+		return 0
+	}
+	pos := r.hash1(key)
+	pos = int(pos/8) * 8
+	row := r.hash2(key)
+	var res uint32 = 0
+	for j := pos; j < r.W+pos; j++ {
+		if kernel.GetBit(row[(j-pos)/8], (j-pos)%8) {
+			res = res ^ r.P[j]
+		}
+	}
+	return res
+
+}
+
+func (r *OKVSECC) DecodewithCheck(key []byte) (uint32, bool) {
+	ok := true
+	if len(key) == 0 {
+		return 0, ok
+	}
+	pos := r.hash1(key)
+	pos = int(pos/8) * 8
+	row := r.hash2(key)
+	var res uint32 = 0
+	for j := pos; j < r.W+pos; j++ {
+		if kernel.GetBit(row[(j-pos)/8], (j-pos)%8) {
+			res = res ^ r.P[j]
+		}
+	}
+	if res > uint32(r.N) {
+		ok = false
+	}
+	return res, ok
+
+}
+
+// DecodeBatch 和 OKVSBK.DecodeBatch 一样，并行解码到调用方给的 out 中
+func (r *OKVSECC) DecodeBatch(keys [][]byte, out []uint32) error {
+	return common.DecodeInto(keys, out, r.Workers, r.Decode)
+}
+
+// Verify 返回解码结果不等于 value 的 key，没有时返回 nil
+func (r *OKVSECC) Verify(kvs []KVECC) []okvs.Mismatch {
+	keys, want := splitKVs(kvs)
+	got := make([]uint32, len(kvs))
+	r.DecodeBatch(keys, got)
+	return common.Mismatches(keys, want, got)
+}
+
+// ParDecode 解码 kvs 中的所有 key，第一个不一致的 value 作为包装了 okvs.ErrValueMismatch 的错误返回。
+// 只需要解码时使用 DecodeBatch，需要所有不一致的位置时使用 Verify
+func (r *OKVSECC) ParDecode(kvs []KVECC) ([]uint32, error) {
+	keys, want := splitKVs(kvs)
+	res := make([]uint32, len(kvs))
+	r.DecodeBatch(keys, res)
+	if ms := common.Mismatches(keys, want, res); len(ms) > 0 {
+		return res, common.ValueMismatch(ms[0].Index, ms[0].Key)
+	}
+	return res, nil
+}
+
+func splitKVs(kvs []KVECC) ([][]byte, []uint32) {
+	keys := make([][]byte, len(kvs))
+	vals := make([]uint32, len(kvs))
+	for i, kv := range kvs {
+		keys[i], vals[i] = kv.Key, kv.Value
+	}
+	return keys, vals
+}
@@ -0,0 +1,64 @@
+package ecdlp
+
+import (
+	"errors"
+	mrand "math/rand"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+// encodeECC 用随机的 key、value 和随机填充编码 n 个 key，返回 OKVSECC 和 kvs
+func encodeECC(t *testing.T, rng *mrand.Rand, n int, opts ...okvs.Option) (*OKVSECC, []KVECC) {
+	t.Helper()
+	r, err := NewOKVSECC(n, append([]okvs.Option{okvs.WithSeed([]byte("ecdlp test seed")), okvs.WithRand(rng)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kvs := make([]KVECC, n)
+	for i := range kvs {
+		kvs[i].Key = make([]byte, 16)
+		rng.Read(kvs[i].Key)
+		kvs[i].Value = rng.Uint32()
+	}
+	if _, err := r.Encode(kvs); err != nil {
+		t.Fatal(err)
+	}
+	return r, kvs
+}
+
+func TestDecodeBatch(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(1))
+	r, kvs := encodeECC(t, rng, 1000, okvs.WithWorkers(3))
+	keys, _ := splitKVs(kvs)
+
+	out := make([]uint32, len(keys))
+	if err := r.DecodeBatch(keys, out); err != nil {
+		t.Fatal(err)
+	}
+	for i, key := range keys {
+		if want := r.Decode(key); out[i] != want {
+			t.Fatalf("DecodeBatch[%d]: got %d, want %d", i, out[i], want)
+		}
+	}
+	if err := r.DecodeBatch(keys, out[:len(keys)-1]); !errors.Is(err, okvs.ErrSizeMismatch) {
+		t.Fatalf("short out: got %v, want ErrSizeMismatch", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(2))
+	r, kvs := encodeECC(t, rng, 1000)
+	// kvs[0] 不参与编码
+	if bad := r.Verify(kvs[1:]); bad != nil {
+		t.Fatalf("Verify: got %v, want nil", bad)
+	}
+
+	const tampered = 417
+	want := kvs[tampered].Value
+	kvs[tampered].Value ^= 1 << 31
+	bad := r.Verify(kvs[1:])
+	if len(bad) != 1 || bad[0].Index != tampered-1 || bad[0].Got != want {
+		t.Fatalf("Verify: got %+v, want index %d", bad, tampered-1)
+	}
+}
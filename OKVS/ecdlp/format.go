@@ -0,0 +1,65 @@
+package ecdlp
+
+import (
+	"io"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+func init() {
+	okvs.RegisterLoader(okvs.VariantOKVSECC, func(h *okvs.Header, payload []byte) (okvs.Store, error) {
+		r, err := okvseccFrom(h, payload)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+// WriteTo writes r as an okvs container with 4-byte values. Rand is not
+// stored.
+func (r *OKVSECC) WriteTo(w io.Writer) (int64, error) {
+	h := &okvs.Header{
+		Variant: okvs.VariantOKVSECC,
+		Flags:   okvs.HashFlag(r.Hash),
+		N:       r.N,
+		M:       r.M,
+		W:       r.W,
+		R:       r.R,
+		Seed:    r.Seed,
+		Tag:     r.Tag,
+	}
+	return common.WriteUint32s(w, h, r.P)
+}
+
+// ReadOKVSECC 读取 OKVSECC.WriteTo 写的容器
+func ReadOKVSECC(rd io.Reader) (*OKVSECC, error) {
+	h, payload, err := okvs.ReadContainer(rd)
+	if err != nil {
+		return nil, err
+	}
+	return okvseccFrom(h, payload)
+}
+
+func okvseccFrom(h *okvs.Header, payload []byte) (*OKVSECC, error) {
+	p, err := common.Uint32Payload(h, okvs.VariantOKVSECC, payload)
+	if err != nil {
+		return nil, err
+	}
+	r := &OKVSECC{
+		N:    h.N,
+		M:    h.M,
+		W:    h.W,
+		B:    h.W / 8,
+		R:    h.R,
+		P:    p,
+		Seed: h.Seed,
+		Tag:  h.Tag,
+		Hash: h.HashMode(),
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,77 @@
+package ecdlp
+
+import (
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// MappedOKVSECC is a read-only OKVSECC whose P is read straight from a
+// memory-mapped file, so opening a table copies nothing and every process
+// mapping the same file shares its pages. It only offers lookups and, like
+// Decode, they may run concurrently. After Close it must not be used.
+type MappedOKVSECC struct {
+	t *common.MappedTable[*OKVSECC]
+}
+
+// OpenMapped maps a file written by OKVSECC.WriteTo or by the legacy
+// SerializeOKVSECC. The container digest is not checked, so opening does not
+// touch P; call CheckDigest for that. Where mmap is unavailable the file is
+// read into memory instead, and where P in the file is not aligned for this
+// host P is decoded into memory; Mapped reports which one happened.
+func OpenMapped(filename string) (*MappedOKVSECC, error) {
+	m, err := common.Map(filename)
+	if err != nil {
+		return nil, err
+	}
+	return newMapped(m)
+}
+
+// newMapped 从 m 解析 OKVSECC，失败时关闭 m
+func newMapped(m *common.Mapping) (*MappedOKVSECC, error) {
+	t, err := common.NewMappedTable(m, okvseccFrom, okvseccFromLegacy, func(r *OKVSECC) []uint32 { return r.P })
+	if err != nil {
+		return nil, err
+	}
+	return &MappedOKVSECC{t: t}, nil
+}
+
+// Decode 和 OKVSECC.Decode 相同
+func (m *MappedOKVSECC) Decode(key []byte) uint32 {
+	return m.t.T.Decode(key)
+}
+
+// DecodewithCheck 和 OKVSECC.DecodewithCheck 相同
+func (m *MappedOKVSECC) DecodewithCheck(key []byte) (uint32, bool) {
+	return m.t.T.DecodewithCheck(key)
+}
+
+// DecodeBatch 和 OKVSECC.DecodeBatch 相同
+func (m *MappedOKVSECC) DecodeBatch(keys [][]byte, out []uint32) error {
+	return m.t.T.DecodeBatch(keys, out)
+}
+
+// Verify 和 OKVSECC.Verify 相同
+func (m *MappedOKVSECC) Verify(kvs []KVECC) []okvs.Mismatch {
+	return m.t.T.Verify(kvs)
+}
+
+// Params 返回文件里的参数
+func (m *MappedOKVSECC) Params() okvs.Params {
+	r := m.t.T
+	return okvs.Params{N: r.N, M: r.M, W: r.W, R: r.R}
+}
+
+// Mapped 返回 Decode 是否直接读映射的文件
+func (m *MappedOKVSECC) Mapped() bool {
+	return m.t.Mapped()
+}
+
+// CheckDigest 检查容器末尾的摘要，会读一遍整个文件；旧格式没有摘要，总是返回 nil
+func (m *MappedOKVSECC) CheckDigest() error {
+	return m.t.CheckDigest()
+}
+
+// Close 解除映射
+func (m *MappedOKVSECC) Close() error {
+	return m.t.Close()
+}
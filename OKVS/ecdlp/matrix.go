@@ -0,0 +1,18 @@
+package ecdlp
+
+import (
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/OurOKVS/OKVS/linear"
+)
+
+// Matrix 返回 keys 对应的解码矩阵，linear.Mul(a, r.P)[i] == r.Decode(keys[i])
+func (r *OKVSECC) Matrix(keys [][]byte) *linear.F2 {
+	a := &linear.F2{N: len(keys), M: r.M, W: r.W, Pos: make([]int, len(keys)), Rows: make([][]byte, len(keys))}
+	common.ParallelFor(len(keys), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			a.Pos[i] = r.hash1(keys[i]) / 8 * 8
+			a.Rows[i] = r.hash2(keys[i])
+		}
+	})
+	return a
+}
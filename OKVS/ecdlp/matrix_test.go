@@ -0,0 +1,30 @@
+package ecdlp
+
+import (
+	mrand "math/rand"
+	"testing"
+
+	"github.com/OurOKVS/OKVS/linear"
+)
+
+func TestMatrix(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(3))
+	r, err := NewOKVSECC(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := range r.P {
+		r.P[j] = rng.Uint32()
+	}
+	keys := make([][]byte, r.N)
+	for i := range keys {
+		keys[i] = make([]byte, 16)
+		rng.Read(keys[i])
+	}
+	got := linear.Mul(r.Matrix(keys), r.P)
+	for i, key := range keys {
+		if want := r.Decode(key); got[i] != want {
+			t.Fatalf("Mul(Matrix, P)[%d] = %d, want %d", i, got[i], want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package ecdlp
+
+import (
+	"math/big"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+func init() {
+	okvs.Register("ecdlp", func(n int, opts ...okvs.Option) (okvs.Store, error) {
+		r, err := NewOKVSECC(n, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+type storeOKVSECC struct{ r *OKVSECC }
+
+// AsStore 把 OKVSECC 包装成 Store，和 OKVSECC.Encode 一样第 0 个 pair 保留不编码
+func (r *OKVSECC) AsStore() okvs.Store { return storeOKVSECC{r} }
+
+func (s storeOKVSECC) Encode(pairs []okvs.Pair) error {
+	kvs := make([]KVECC, len(pairs))
+	for i := range pairs {
+		v, err := common.ToUint32(pairs[i].Value)
+		if err != nil {
+			return err
+		}
+		kvs[i] = KVECC{Key: pairs[i].Key, Value: v}
+	}
+	_, err := s.r.Encode(kvs)
+	return err
+}
+
+func (s storeOKVSECC) Decode(key []byte) *big.Int {
+	return new(big.Int).SetUint64(uint64(s.r.Decode(key)))
+}
+
+func (s storeOKVSECC) DecodeBatch(keys [][]byte) []*big.Int {
+	return common.DecodeBatch(keys, s.Decode)
+}
+
+func (s storeOKVSECC) Size() int { return len(s.r.P) * 4 }
+
+func (s storeOKVSECC) Params() okvs.Params {
+	return okvs.Params{N: s.r.N, M: s.r.M, W: s.r.W, R: s.r.R}
+}
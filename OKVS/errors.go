@@ -0,0 +1,43 @@
+package okvs
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSizeMismatch 表示传入的 kv 数量和 r.N 不一致
+	ErrSizeMismatch = errors.New("okvs: r.N must equal to len(kvs)")
+	// ErrSingularSystem 表示带状线性系统在某一行找不到主元
+	ErrSingularSystem = errors.New("okvs: band system is singular")
+	// ErrValueMismatch 表示解码结果和给定的 value 不一致
+	ErrValueMismatch = errors.New("okvs: decoded value does not match")
+	// ErrValueRange 表示 value 超出了后端支持的宽度
+	ErrValueRange = errors.New("okvs: value does not fit in the value width")
+	// ErrIncompatible 表示两个 OKVS 的参数不同，不能逐位置组合
+	ErrIncompatible = errors.New("okvs: structures have different parameters")
+	// ErrFinished 表示 Encoder 已经调用过 Finish，不能再加入 key
+	ErrFinished = errors.New("okvs: encoder already finished")
+)
+
+// SingularError records the row of the sorted system that has no pivot and
+// the key it was built from. It matches ErrSingularSystem with errors.Is.
+type SingularError struct {
+	Row int
+	Key []byte
+}
+
+func (e *SingularError) Error() string {
+	return fmt.Sprintf("okvs: fail to generate at %dth row (key %x)", e.Row, e.Key)
+}
+
+func (e *SingularError) Unwrap() error { return ErrSingularSystem }
+
+// Mismatch is one key-value pair whose decoded value differs from the value
+// it was encoded with, as reported by Verify.
+type Mismatch struct {
+	Index int // 在 kvs 中的下标
+	Key   []byte
+	Want  uint32 // kvs 中的 value
+	Got   uint32 // Decode 的结果
+}
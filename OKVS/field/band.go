@@ -0,0 +1,242 @@
+package field
+
+import (
+	"io"
+
+	"github.com/OurOKVS/OKVS/internal/band"
+)
+
+// Band is a banded system over any Field: sorted row i has the coefficients
+// Rows[i*W:(i+1)*W] in columns [Pos[i], Pos[i]+W) and right-hand side
+// Vals[i]. Encoding an OKVS over a new value domain only needs a Field
+// instance, the row coefficients and the values.
+type Band[E comparable, F Field[E]] struct {
+	F       F
+	N, M, W int
+	Pos     []int // 第 i 行的起始列
+	Idx     []int // 第 i 行对应的原始下标
+	Rows    []E   // N*W
+	Vals    []E   // N
+}
+
+// NewBand sorts the rows by start position and allocates zeroed rows and
+// values. pos[i] is the start column of the original row i and must lie in
+// [0, m-w].
+func NewBand[E comparable, F Field[E]](f F, m, w int, pos []int) *Band[E, F] {
+	n := len(pos)
+	b := &Band[E, F]{
+		F:    f,
+		N:    n,
+		M:    m,
+		W:    w,
+		Pos:  make([]int, n),
+		Idx:  make([]int, n),
+		Rows: make([]E, n*w),
+		Vals: make([]E, n),
+	}
+	// 计数排序，Pos 的范围只有 m
+	count := make([]int, m+1)
+	for _, p := range pos {
+		count[p+1]++
+	}
+	for c := 1; c <= m; c++ {
+		count[c] += count[c-1]
+	}
+	for i, p := range pos {
+		k := count[p]
+		count[p]++
+		b.Pos[k] = p
+		b.Idx[k] = i
+	}
+	return b
+}
+
+// Row returns the coefficients of sorted row i.
+func (b *Band[E, F]) Row(i int) []E { return b.Rows[i*b.W : (i+1)*b.W] }
+
+// Eliminate brings the rows to echelon form in place, scaling every pivot
+// row so that its pivot is one. On success it returns the pivot column of
+// every sorted row; otherwise it returns the sorted index of the first row
+// whose leading coefficient is not invertible.
+func (b *Band[E, F]) Eliminate() ([]int, int) {
+	f, w := b.F, b.W
+	piv := make([]int, b.N)
+	for i := 0; i < b.N; i++ {
+		row := b.Row(i)
+		j := 0
+		for j < w && f.IsZero(row[j]) {
+			j++
+		}
+		if j == w {
+			return nil, i
+		}
+		inv := f.Inv(row[j])
+		if f.IsZero(inv) {
+			return nil, i
+		}
+		pi := b.Pos[i]
+		piv[i] = pi + j
+		for s := j; s < w; s++ {
+			row[s] = f.Mul(row[s], inv)
+		}
+		b.Vals[i] = f.Mul(b.Vals[i], inv)
+		for k := i + 1; k < b.N && b.Pos[k] <= piv[i]; k++ {
+			rk := b.Row(k)
+			d := b.Pos[k] - pi
+			c := rk[j-d]
+			if f.IsZero(c) {
+				continue
+			}
+			for s := j - d; s < w-d; s++ {
+				rk[s] = f.Sub(rk[s], f.Mul(c, row[s+d]))
+			}
+			b.Vals[k] = f.Sub(b.Vals[k], f.Mul(c, b.Vals[i]))
+		}
+	}
+	return piv, -1
+}
+
+// BackSubstitute solves for the pivot slots of p, which has M entries.
+// Non-pivot slots are read as they are, so callers may fill them with random
+// elements first.
+func (b *Band[E, F]) BackSubstitute(piv []int, p []E) {
+	f := b.F
+	for i := b.N - 1; i >= 0; i-- {
+		pi := b.Pos[i]
+		res := b.Vals[i]
+		for s, c := range b.Row(i) {
+			if pi+s == piv[i] || f.IsZero(c) {
+				continue
+			}
+			res = f.Sub(res, f.Mul(c, p[pi+s]))
+		}
+		p[piv[i]] = res
+	}
+}
+
+// Solve returns P with Row(i)·P[Pos[i]:Pos[i]+W] = Vals[i] for every row.
+// When rnd is not nil the non-pivot slots are drawn from it, otherwise they
+// are zero. If the system cannot be solved, Solve returns the sorted index
+// of the failing row. Rings that implement TwoAdic are solved by lifting,
+// which leaves Rows and Vals untouched; otherwise they are eliminated in
+// place.
+func (b *Band[E, F]) Solve(rnd io.Reader) ([]E, int, error) {
+	if t, ok := any(b.F).(TwoAdic[E]); ok {
+		return b.lift(t, rnd)
+	}
+	piv, fail := b.Eliminate()
+	if fail >= 0 {
+		return nil, fail, nil
+	}
+	p := make([]E, b.M)
+	if err := b.fill(rnd, p, piv); err != nil {
+		return nil, -1, err
+	}
+	b.BackSubstitute(piv, p)
+	return p, -1, nil
+}
+
+// fill 用 rnd 填充非主元位置，rnd 为空时什么也不做
+func (b *Band[E, F]) fill(rnd io.Reader, p []E, piv []int) error {
+	if rnd == nil {
+		return nil
+	}
+	pivot := make([]bool, b.M)
+	for _, c := range piv {
+		pivot[c] = true
+	}
+	for j := range p {
+		if pivot[j] {
+			continue
+		}
+		v, err := b.F.Rand(rnd)
+		if err != nil {
+			return err
+		}
+		p[j] = v
+	}
+	return nil
+}
+
+// lift 在 Z_{2^k} 上求解：先把行模 2 后在 GF(2) 上消元并记录行操作，
+// 然后每一轮用残差 Vals - A·P 的第 t 位解一次模 2 的系统，把解乘以 2^t 加到 P 上，
+// 这样第 t 轮之后 A·P 和 Vals 模 2^(t+1) 相等。A 模 2 满秩时一定成功
+func (b *Band[E, F]) lift(t TwoAdic[E], rnd io.Reader) ([]E, int, error) {
+	f, n, w := b.F, b.N, b.W
+	s := &band.Solver{
+		N:      n,
+		M:      b.M,
+		W:      w,
+		RW:     (w + 63) / 64,
+		VW:     1,
+		Pos:    b.Pos,
+		Idx:    b.Idx,
+		Vals:   make([]uint64, n),
+		Record: true,
+	}
+	s.Rows = make([]uint64, n*s.RW)
+	for i := 0; i < n; i++ {
+		dst := s.Row(i)
+		for j, c := range b.Row(i) {
+			if t.Bit(c, 0) {
+				dst[j/64] |= 1 << (j % 64)
+			}
+		}
+	}
+	piv, fail := s.Eliminate()
+	if fail >= 0 {
+		return nil, fail, nil
+	}
+	p := make([]E, b.M)
+	if err := b.fill(rnd, p, piv); err != nil {
+		return nil, -1, err
+	}
+	res := make([]E, n)
+	for i := range res {
+		res[i] = b.residual(i, p)
+	}
+	y := make([]uint64, b.M)
+	for k := 0; k < t.Bits(); k++ {
+		for i := range res {
+			s.Vals[i] = 0
+			if t.Bit(res[i], k) {
+				s.Vals[i] = 1
+			}
+		}
+		s.Replay()
+		for j := range y {
+			y[j] = 0
+		}
+		s.BackSubstitute(piv, y)
+		step := t.Pow2(k)
+		for _, c := range piv {
+			if y[c] == 1 {
+				p[c] = f.Add(p[c], step)
+			}
+		}
+		if k+1 == t.Bits() {
+			break
+		}
+		// 残差减去 A·(2^k·y)，只有主元列上的 y 可能非零
+		for i := range res {
+			pi := b.Pos[i]
+			for j, c := range b.Row(i) {
+				if y[pi+j] == 1 {
+					res[i] = f.Sub(res[i], f.Mul(c, step))
+				}
+			}
+		}
+	}
+	return p, -1, nil
+}
+
+// residual 返回 Vals[i] - Row(i)·P
+func (b *Band[E, F]) residual(i int, p []E) E {
+	f := b.F
+	res := b.Vals[i]
+	pi := b.Pos[i]
+	for j, c := range b.Row(i) {
+		res = f.Sub(res, f.Mul(c, p[pi+j]))
+	}
+	return res
+}
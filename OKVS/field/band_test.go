@@ -0,0 +1,115 @@
+package field_test
+
+import (
+	"io"
+	mrand "math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/OurOKVS/OKVS/field"
+)
+
+// checkBand 用 coef 生成的系数和随机的 value 求解一个带状系统，检查每一行的
+// Row·P 都等于原来的 value。Solve 可能原地消元，所以先保存一份行和 value
+func checkBand[E comparable, F field.Field[E]](t *testing.T, f F, coef func(rng *mrand.Rand) E) {
+	t.Helper()
+	const n, m, w = 500, 650, 64
+	rng := mrand.New(mrand.NewSource(1))
+	for _, fill := range []bool{false, true} {
+		pos := make([]int, n)
+		for i := range pos {
+			pos[i] = rng.Intn(m - w + 1)
+		}
+		b := field.NewBand[E](f, m, w, pos)
+		for i := 0; i < n; i++ {
+			row := b.Row(i)
+			for j := range row {
+				row[j] = coef(rng)
+			}
+			v, err := f.Rand(rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.Vals[i] = v
+		}
+		rows := append([]E(nil), b.Rows...)
+		vals := append([]E(nil), b.Vals...)
+		var rnd io.Reader
+		if fill {
+			rnd = rng
+		}
+		p, fail, err := b.Solve(rnd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fail >= 0 {
+			t.Fatalf("fill = %v: row %d is singular", fill, fail)
+		}
+		if len(p) != m {
+			t.Fatalf("len(P) = %d, want %d", len(p), m)
+		}
+		for i := 0; i < n; i++ {
+			got := f.Zero()
+			for j, c := range rows[i*w : (i+1)*w] {
+				got = f.Add(got, f.Mul(c, p[b.Pos[i]+j]))
+			}
+			if got != vals[i] {
+				t.Fatalf("fill = %v: row %d (key %d) decodes to %v, want %v", fill, i, b.Idx[i], got, vals[i])
+			}
+		}
+		if _, ok := any(f).(field.TwoAdic[E]); ok && (!reflect.DeepEqual(b.Rows, rows) || !reflect.DeepEqual(b.Vals, vals)) {
+			t.Fatalf("fill = %v: lifting changed Rows or Vals", fill)
+		}
+	}
+}
+
+// randCoef 返回 f 中均匀随机的系数，会包含 0
+func randCoef[E comparable](f field.Field[E]) func(rng *mrand.Rand) E {
+	return func(rng *mrand.Rand) E {
+		c, err := f.Rand(rng)
+		if err != nil {
+			panic(err)
+		}
+		return c
+	}
+}
+
+func TestBandPrime(t *testing.T) {
+	checkBand[uint64](t, field.Mersenne61{}, randCoef[uint64](field.Mersenne61{}))
+	f1, err := field.NewMont[[1]uint64](small)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBand[[1]uint64](t, f1, randCoef[[1]uint64](f1))
+	f2, err := field.NewMont[[2]uint64](p127)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBand[[2]uint64](t, f2, randCoef[[2]uint64](f2))
+	f4, err := field.NewMont[[4]uint64](field.P256Order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkBand[[4]uint64](t, f4, randCoef[[4]uint64](f4))
+}
+
+func TestBandBinary(t *testing.T) {
+	checkBand[uint64](t, field.GF64{}, randCoef[uint64](field.GF64{}))
+	checkBand[[2]uint64](t, field.GF128{}, randCoef[[2]uint64](field.GF128{}))
+}
+
+// BitVec32 的行只能是掩码，这正是 OKVSBK 的 GF(2) 系数
+func TestBandBitVec32(t *testing.T) {
+	f := field.BitVec32{}
+	checkBand[uint32](t, f, func(rng *mrand.Rand) uint32 { return f.Coef(rng.Intn(2) == 1) })
+}
+
+// Ring64 的系数有一半是偶数，偶数不可逆，只能通过 TwoAdic 逐位提升求解
+func TestBandRing64(t *testing.T) {
+	checkBand[uint64](t, field.Ring64{}, func(rng *mrand.Rand) uint64 {
+		if rng.Intn(4) == 0 {
+			return 2 * uint64(rng.Intn(8)) // 0、2、4 这类小的偶数
+		}
+		return rng.Uint64()
+	})
+}
@@ -0,0 +1,146 @@
+package field
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// BitVec32 is GF(2)^32 as a product of 32 copies of GF(2): Add is XOR and
+// Mul is AND. A GF(2) coefficient b is the mask 0 or 0xffffffff, so Mul
+// scales a 32-bit value by it; this is the domain of OKVSBK. The mask is
+// the only unit, so band rows must hold masks only.
+type BitVec32 struct{}
+
+// Coef 返回 GF(2) 系数 b 对应的掩码
+func (BitVec32) Coef(b bool) uint32 {
+	if b {
+		return ^uint32(0)
+	}
+	return 0
+}
+
+func (BitVec32) Zero() uint32 { return 0 }
+
+func (BitVec32) One() uint32 { return ^uint32(0) }
+
+func (BitVec32) IsZero(a uint32) bool { return a == 0 }
+
+func (BitVec32) Add(a, b uint32) uint32 { return a ^ b }
+
+func (BitVec32) Sub(a, b uint32) uint32 { return a ^ b }
+
+func (BitVec32) Mul(a, b uint32) uint32 { return a & b }
+
+// Inv 只对全 1 的掩码有逆，其余返回零
+func (BitVec32) Inv(a uint32) uint32 {
+	if a == ^uint32(0) {
+		return a
+	}
+	return 0
+}
+
+func (BitVec32) Rand(rnd io.Reader) (uint32, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(rnd, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf[:]), nil
+}
+
+// clmul 返回 a 和 b 的无进位乘积 (hi, lo)
+func clmul(a, b uint64) (hi, lo uint64) {
+	for i := 0; i < 64; i++ {
+		m := -(b >> i & 1)
+		lo ^= a << i & m
+		if i > 0 {
+			hi ^= a >> (64 - i) & m
+		}
+	}
+	return hi, lo
+}
+
+// GF64 is GF(2^64) modulo x^64 + x^4 + x^3 + x + 1. Bit i of an element is
+// the coefficient of x^i; Mul is a carry-less multiplication followed by
+// folding the high word back with the reduction polynomial.
+type GF64 struct{}
+
+func (GF64) Zero() uint64 { return 0 }
+
+func (GF64) One() uint64 { return 1 }
+
+func (GF64) IsZero(a uint64) bool { return a == 0 }
+
+func (GF64) Add(a, b uint64) uint64 { return a ^ b }
+
+func (GF64) Sub(a, b uint64) uint64 { return a ^ b }
+
+func (GF64) Mul(a, b uint64) uint64 {
+	hi, lo := clmul(a, b)
+	// hi·x^64 = hi·(x^4+x^3+x+1)，溢出的 4 位再折叠一次
+	o := hi>>63 ^ hi>>61 ^ hi>>60
+	return lo ^ hi ^ hi<<1 ^ hi<<3 ^ hi<<4 ^ o ^ o<<1 ^ o<<3 ^ o<<4
+}
+
+// Inv 返回 a^(2^64-2)，a 为零时返回零
+func (f GF64) Inv(a uint64) uint64 {
+	res := a
+	for i := 0; i < 62; i++ {
+		res = f.Mul(f.Mul(res, res), a)
+	}
+	return f.Mul(res, res)
+}
+
+func (GF64) Rand(rnd io.Reader) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(rnd, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+// GF128 is GF(2^128) modulo x^128 + x^7 + x^2 + x + 1. An element is two
+// words, the coefficients of x^0..x^63 first.
+type GF128 struct{}
+
+func (GF128) Zero() [2]uint64 { return [2]uint64{} }
+
+func (GF128) One() [2]uint64 { return [2]uint64{1, 0} }
+
+func (GF128) IsZero(a [2]uint64) bool { return a == [2]uint64{} }
+
+func (GF128) Add(a, b [2]uint64) [2]uint64 { return [2]uint64{a[0] ^ b[0], a[1] ^ b[1]} }
+
+func (GF128) Sub(a, b [2]uint64) [2]uint64 { return [2]uint64{a[0] ^ b[0], a[1] ^ b[1]} }
+
+func (GF128) Mul(a, b [2]uint64) [2]uint64 {
+	// Karatsuba：三次 64 位无进位乘法得到 256 位的乘积 c
+	h0, l0 := clmul(a[0], b[0])
+	h1, l1 := clmul(a[1], b[1])
+	hm, lm := clmul(a[0]^a[1], b[0]^b[1])
+	hm ^= h0 ^ h1
+	lm ^= l0 ^ l1
+	c0, c1, c2, c3 := l0, h0^lm, l1^hm, h1
+	// c3·x^192 和 c2·x^128 依次用 x^128 = x^7+x^2+x+1 折叠
+	c1 ^= c3 ^ c3<<1 ^ c3<<2 ^ c3<<7
+	c2 ^= c3>>63 ^ c3>>62 ^ c3>>57
+	c0 ^= c2 ^ c2<<1 ^ c2<<2 ^ c2<<7
+	c1 ^= c2>>63 ^ c2>>62 ^ c2>>57
+	return [2]uint64{c0, c1}
+}
+
+// Inv 返回 a^(2^128-2)，a 为零时返回零
+func (f GF128) Inv(a [2]uint64) [2]uint64 {
+	res := a
+	for i := 0; i < 126; i++ {
+		res = f.Mul(f.Mul(res, res), a)
+	}
+	return f.Mul(res, res)
+}
+
+func (GF128) Rand(rnd io.Reader) ([2]uint64, error) {
+	var buf [16]byte
+	if _, err := io.ReadFull(rnd, buf[:]); err != nil {
+		return [2]uint64{}, err
+	}
+	return [2]uint64{binary.LittleEndian.Uint64(buf[:8]), binary.LittleEndian.Uint64(buf[8:])}, nil
+}
@@ -0,0 +1,30 @@
+package field
+
+import (
+	"io"
+)
+
+// Field is what the generic band solver needs from a value domain. Elements
+// are plain comparable values. Inv returns zero for elements that have no
+// inverse, which for a field is only zero itself; Rand draws a uniform
+// element and is used to fill the non-pivot slots of an OKVS.
+type Field[E comparable] interface {
+	Zero() E
+	One() E
+	IsZero(a E) bool
+	Add(a, b E) E
+	Sub(a, b E) E
+	Mul(a, b E) E
+	Inv(a E) E
+	Rand(rnd io.Reader) (E, error)
+}
+
+// TwoAdic is implemented by rings Z_{2^k}, whose non-units are the even
+// elements. Band.Solve does not eliminate over such a ring directly, since
+// even entries left of a pivot would spill out of the band; it solves the
+// system mod 2 and lifts the solution one bit at a time instead.
+type TwoAdic[E comparable] interface {
+	Bits() int           // k
+	Bit(a E, t int) bool // a 的第 t 位
+	Pow2(t int) E        // 2^t
+}
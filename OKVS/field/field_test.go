@@ -0,0 +1,185 @@
+package field_test
+
+import (
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/OurOKVS/OKVS/field"
+)
+
+// ref 是用 math/big 写的参考实现，元素都是 [0, q) 或次数小于 k 的多项式
+type ref struct {
+	rand func(rng *mrand.Rand) *big.Int
+	add  func(a, b *big.Int) *big.Int
+	sub  func(a, b *big.Int) *big.Int
+	mul  func(a, b *big.Int) *big.Int
+	inv  func(a *big.Int) *big.Int
+	exp  func(a, e *big.Int) *big.Int
+}
+
+func primeRef(q *big.Int) ref {
+	mod := func(x *big.Int) *big.Int { return x.Mod(x, q) }
+	return ref{
+		rand: func(rng *mrand.Rand) *big.Int { return new(big.Int).Rand(rng, q) },
+		add:  func(a, b *big.Int) *big.Int { return mod(new(big.Int).Add(a, b)) },
+		sub:  func(a, b *big.Int) *big.Int { return mod(new(big.Int).Sub(a, b)) },
+		mul:  func(a, b *big.Int) *big.Int { return mod(new(big.Int).Mul(a, b)) },
+		inv:  func(a *big.Int) *big.Int { return new(big.Int).ModInverse(a, q) },
+		exp:  func(a, e *big.Int) *big.Int { return new(big.Int).Exp(a, e, q) },
+	}
+}
+
+// binaryRef 是模 x^k + low 的 GF(2^k)，bit i 是 x^i 的系数
+func binaryRef(k int, low int64) ref {
+	poly := new(big.Int).SetBit(big.NewInt(low), k, 1)
+	r := ref{
+		rand: func(rng *mrand.Rand) *big.Int {
+			return new(big.Int).Rand(rng, new(big.Int).Lsh(big.NewInt(1), uint(k)))
+		},
+		add: func(a, b *big.Int) *big.Int { return new(big.Int).Xor(a, b) },
+		sub: func(a, b *big.Int) *big.Int { return new(big.Int).Xor(a, b) },
+		mul: func(a, b *big.Int) *big.Int {
+			c := new(big.Int)
+			for i := 0; i < b.BitLen(); i++ {
+				if b.Bit(i) == 1 {
+					c.Xor(c, new(big.Int).Lsh(a, uint(i)))
+				}
+			}
+			for i := c.BitLen() - 1; i >= k; i-- {
+				if c.Bit(i) == 1 {
+					c.Xor(c, new(big.Int).Lsh(poly, uint(i-k)))
+				}
+			}
+			return c
+		},
+	}
+	r.exp = func(a, e *big.Int) *big.Int {
+		res := big.NewInt(1)
+		for i := e.BitLen() - 1; i >= 0; i-- {
+			res = r.mul(res, res)
+			if e.Bit(i) == 1 {
+				res = r.mul(res, a)
+			}
+		}
+		return res
+	}
+	r.inv = func(a *big.Int) *big.Int {
+		return r.exp(a, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(k)), big.NewInt(2)))
+	}
+	return r
+}
+
+// exp 用 f 的乘法做平方-乘，和参考的 exp 比较就检查了一长串 Mul
+func exp[E comparable](f field.Field[E], a E, e *big.Int) E {
+	res := f.One()
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		res = f.Mul(res, res)
+		if e.Bit(i) == 1 {
+			res = f.Mul(res, a)
+		}
+	}
+	return res
+}
+
+func checkField[E comparable](t *testing.T, f field.Field[E], from func(*big.Int) E, to func(E) *big.Int, r ref, edge ...*big.Int) {
+	t.Helper()
+	rng := mrand.New(mrand.NewSource(1))
+	for it := 0; it < 200; it++ {
+		a, b := r.rand(rng), r.rand(rng)
+		if it < len(edge) {
+			a, b = edge[it], edge[len(edge)-1-it]
+		}
+		ea, eb := from(a), from(b)
+		if got := to(ea); got.Cmp(a) != 0 {
+			t.Fatalf("round trip of %v gives %v", a, got)
+		}
+		check := func(op string, got E, want *big.Int) {
+			t.Helper()
+			if to(got).Cmp(want) != 0 {
+				t.Fatalf("%s(%v, %v) = %v, want %v", op, a, b, to(got), want)
+			}
+		}
+		check("Add", f.Add(ea, eb), r.add(a, b))
+		check("Sub", f.Sub(ea, eb), r.sub(a, b))
+		check("Mul", f.Mul(ea, eb), r.mul(a, b))
+		e := new(big.Int).Rand(rng, new(big.Int).Lsh(big.NewInt(1), 200))
+		check("Exp", exp(f, ea, e), r.exp(a, e))
+		if a.Sign() == 0 {
+			check("Inv", f.Inv(ea), new(big.Int))
+			continue
+		}
+		check("Inv", f.Inv(ea), r.inv(a))
+		if !f.IsZero(f.Sub(f.Mul(ea, f.Inv(ea)), f.One())) {
+			t.Fatalf("%v times its inverse is not one", a)
+		}
+	}
+}
+
+func checkMont[E field.Limbs](t *testing.T, q *big.Int) {
+	t.Helper()
+	f, err := field.NewMont[E](q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qm1 := new(big.Int).Sub(q, big.NewInt(1))
+	checkField[E](t, f, f.FromBig, f.ToBig, primeRef(q), big.NewInt(0), big.NewInt(1), qm1)
+}
+
+var (
+	p127  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	p256  = mustHex("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff")
+	p64   = new(big.Int).SetUint64(1<<64 - 59)
+	ones  = func(k uint) *big.Int { return new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), k), big.NewInt(1)) }
+	small = big.NewInt(1000003)
+)
+
+func mustHex(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic(s)
+	}
+	return x
+}
+
+func TestMersenne61(t *testing.T) {
+	f := field.Mersenne61{}
+	q := field.Mersenne61Q
+	checkField[uint64](t, f, f.FromBig, f.ToBig, primeRef(q), big.NewInt(0), big.NewInt(1), new(big.Int).Sub(q, big.NewInt(1)))
+}
+
+func TestMont1(t *testing.T) {
+	for _, q := range []*big.Int{small, field.Mersenne61Q, p64} {
+		checkMont[[1]uint64](t, q)
+	}
+}
+
+func TestMont2(t *testing.T) {
+	for _, q := range []*big.Int{field.Mersenne61Q, p127} {
+		checkMont[[2]uint64](t, q)
+	}
+}
+
+func TestMont4(t *testing.T) {
+	for _, q := range []*big.Int{p127, field.P256Order, field.Curve25519Order, p256} {
+		checkMont[[4]uint64](t, q)
+	}
+}
+
+func TestGF64(t *testing.T) {
+	from := func(x *big.Int) uint64 { return x.Uint64() }
+	to := func(a uint64) *big.Int { return new(big.Int).SetUint64(a) }
+	checkField[uint64](t, field.GF64{}, from, to, binaryRef(64, 0x1b), big.NewInt(0), big.NewInt(1), ones(64))
+}
+
+func TestGF128(t *testing.T) {
+	mask := ones(64)
+	from := func(x *big.Int) [2]uint64 {
+		return [2]uint64{new(big.Int).And(x, mask).Uint64(), new(big.Int).Rsh(x, 64).Uint64()}
+	}
+	to := func(a [2]uint64) *big.Int {
+		x := new(big.Int).Lsh(new(big.Int).SetUint64(a[1]), 64)
+		return x.Or(x, new(big.Int).SetUint64(a[0]))
+	}
+	checkField[[2]uint64](t, field.GF128{}, from, to, binaryRef(128, 0x87), big.NewInt(0), big.NewInt(1), ones(128))
+}
@@ -0,0 +1,80 @@
+package field
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+	"math/bits"
+)
+
+const m61 = 1<<61 - 1
+
+// Mersenne61 is Z_q for q = 2^61-1. Elements are uint64 in [0, q) and
+// reduction is a shift and an add, so no Montgomery form is needed.
+type Mersenne61 struct{}
+
+func (Mersenne61) Modulus() *big.Int { return new(big.Int).Set(Mersenne61Q) }
+
+func (Mersenne61) Zero() uint64 { return 0 }
+
+func (Mersenne61) One() uint64 { return 1 }
+
+func (Mersenne61) IsZero(a uint64) bool { return a == 0 }
+
+func reduce61(x uint64) uint64 {
+	x = x&m61 + x>>61
+	if x >= m61 {
+		x -= m61
+	}
+	return x
+}
+
+func (Mersenne61) Add(a, b uint64) uint64 { return reduce61(a + b) }
+
+func (Mersenne61) Sub(a, b uint64) uint64 { return reduce61(a + m61 - b) }
+
+func (Mersenne61) Neg(a uint64) uint64 { return reduce61(m61 - a) }
+
+func (Mersenne61) Mul(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	// a·b = hi·2^64 + lo = (hi<<3 | lo>>61)·2^61 + (lo & q)
+	return reduce61(lo&m61 + (hi<<3 | lo>>61))
+}
+
+// Inv 返回 a^(q-2)，a 为零时返回零
+func (f Mersenne61) Inv(a uint64) uint64 {
+	res := uint64(1)
+	for e := uint64(m61 - 2); e != 0; e >>= 1 {
+		if e&1 == 1 {
+			res = f.Mul(res, a)
+		}
+		a = f.Mul(a, a)
+	}
+	return res
+}
+
+// Rand 从 rnd 中取一个 [0, q) 中的均匀元素
+func (Mersenne61) Rand(rnd io.Reader) (uint64, error) {
+	v, err := rand.Int(rnd, Mersenne61Q)
+	if err != nil {
+		return 0, err
+	}
+	return v.Uint64(), nil
+}
+
+func (Mersenne61) FromUint64(x uint64) uint64 { return reduce61(x&m61 + x>>61) }
+
+func (Mersenne61) FromBig(x *big.Int) uint64 {
+	return new(big.Int).Mod(x, Mersenne61Q).Uint64()
+}
+
+func (Mersenne61) ToBig(a uint64) *big.Int { return new(big.Int).SetUint64(a) }
+
+// SetBytes 返回大端整数 b mod q，和 new(big.Int).SetBytes(b).Mod(q) 相同
+func (f Mersenne61) SetBytes(b []byte) uint64 {
+	var acc uint64
+	for _, x := range b {
+		acc = reduce61(f.Mul(acc, 256) + uint64(x))
+	}
+	return acc
+}
@@ -0,0 +1,247 @@
+// Package field implements fixed-width prime-field arithmetic on uint64
+// limbs for the OKVS solvers. Elements are plain values, so no operation
+// allocates; math/big is only used to set up constants and to convert at
+// the edges.
+package field
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	"math/bits"
+)
+
+// ErrModulus 表示模数不适合所选的实现
+var ErrModulus = errors.New("field: unsupported modulus")
+
+var (
+	// Mersenne61Q 是 2^61-1
+	Mersenne61Q = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 61), big.NewInt(1))
+	// P256Order 是 NIST P-256 的群阶
+	P256Order, _ = new(big.Int).SetString("ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632551", 16)
+	// Curve25519Order 是 Curve25519 素数阶子群的阶 2^252 + 27742317777372353535851937790883648493
+	Curve25519Order, _ = new(big.Int).SetString("1000000000000000000000000000000014def9dea2f79cd65812631a5cf5d3ed", 16)
+)
+
+// Limbs 是 Montgomery 元素的表示，小端的 64 位 limb
+type Limbs interface {
+	[1]uint64 | [2]uint64 | [4]uint64
+}
+
+// Mont is Z_q for an odd q < 2^(64·len(E)) with elements kept in Montgomery
+// form, multiplied with the CIOS method.
+type Mont[E Limbs] struct {
+	q    E
+	qinv uint64 // -q^{-1} mod 2^64
+	r2   E      // R^2 mod q
+	one  E      // R mod q
+	c64  E      // 2^64 mod q，Montgomery 形式
+	exp  E      // q-2，求逆用
+	mod  *big.Int
+}
+
+// Fp64, Fp128 和 Fp256 是 1、2、4 个 limb 的 Montgomery 域
+type (
+	Fp64  = Mont[[1]uint64]
+	Fp128 = Mont[[2]uint64]
+	Fp256 = Mont[[4]uint64]
+)
+
+// NewMont 为奇数模数 q 准备常数，q 必须大于 2 且能放进 E
+func NewMont[E Limbs](q *big.Int) (*Mont[E], error) {
+	var e E
+	if q.Sign() <= 0 || q.Bit(0) == 0 || q.BitLen() > 64*len(e) || q.Cmp(big.NewInt(3)) < 0 {
+		return nil, ErrModulus
+	}
+	m := &Mont[E]{mod: new(big.Int).Set(q)}
+	m.q = toLimbs[E](q)
+	// Newton 迭代求 q^{-1} mod 2^64
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - m.q[0]*inv
+	}
+	m.qinv = -inv
+	r := new(big.Int).Lsh(big.NewInt(1), uint(64*len(e)))
+	m.one = toLimbs[E](new(big.Int).Mod(r, q))
+	m.r2 = toLimbs[E](new(big.Int).Mod(new(big.Int).Mul(r, r), q))
+	m.exp = toLimbs[E](new(big.Int).Sub(q, big.NewInt(2)))
+	m.c64 = m.FromBig(new(big.Int).Lsh(big.NewInt(1), 64))
+	return m, nil
+}
+
+func toLimbs[E Limbs](x *big.Int) E {
+	var e E
+	var buf [32]byte
+	x.FillBytes(buf[32-8*len(e):])
+	for i := 0; i < len(e); i++ {
+		off := 32 - 8*(i+1)
+		for k := 0; k < 8; k++ {
+			e[i] = e[i]<<8 | uint64(buf[off+k])
+		}
+	}
+	return e
+}
+
+// Modulus 返回 q 的副本
+func (m *Mont[E]) Modulus() *big.Int { return new(big.Int).Set(m.mod) }
+
+func (m *Mont[E]) Zero() E { var e E; return e }
+
+func (m *Mont[E]) One() E { return m.one }
+
+func (m *Mont[E]) IsZero(a E) bool { var z E; return a == z }
+
+// Add 返回 a+b mod q
+func (m *Mont[E]) Add(a, b E) E {
+	var s, d E
+	var c, br uint64
+	for i := 0; i < len(s); i++ {
+		s[i], c = bits.Add64(a[i], b[i], c)
+	}
+	for i := 0; i < len(d); i++ {
+		d[i], br = bits.Sub64(s[i], m.q[i], br)
+	}
+	if c != 0 || br == 0 {
+		return d
+	}
+	return s
+}
+
+// Sub 返回 a-b mod q
+func (m *Mont[E]) Sub(a, b E) E {
+	var d E
+	var br, c uint64
+	for i := 0; i < len(d); i++ {
+		d[i], br = bits.Sub64(a[i], b[i], br)
+	}
+	if br != 0 {
+		for i := 0; i < len(d); i++ {
+			d[i], c = bits.Add64(d[i], m.q[i], c)
+		}
+	}
+	return d
+}
+
+// Neg 返回 -a mod q
+func (m *Mont[E]) Neg(a E) E { return m.Sub(m.Zero(), a) }
+
+// Mul 返回 a·b·R^{-1} mod q，两个 Montgomery 形式的元素相乘仍是 Montgomery 形式
+func (m *Mont[E]) Mul(a, b E) E {
+	n := len(a)
+	var t [6]uint64
+	for i := 0; i < n; i++ {
+		var c, cc uint64
+		for j := 0; j < n; j++ {
+			hi, lo := bits.Mul64(a[j], b[i])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j], c = lo, hi
+		}
+		t[n], cc = bits.Add64(t[n], c, 0)
+		t[n+1] = cc
+
+		mq := t[0] * m.qinv
+		hi, lo := bits.Mul64(mq, m.q[0])
+		_, cc = bits.Add64(lo, t[0], 0)
+		c = hi + cc
+		for j := 1; j < n; j++ {
+			hi, lo = bits.Mul64(mq, m.q[j])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j-1], c = lo, hi
+		}
+		t[n-1], cc = bits.Add64(t[n], c, 0)
+		t[n] = t[n+1] + cc
+	}
+	var s, d E
+	for i := 0; i < n; i++ {
+		s[i] = t[i]
+	}
+	var br uint64
+	for i := 0; i < len(d); i++ {
+		d[i], br = bits.Sub64(s[i], m.q[i], br)
+	}
+	if t[n] != 0 || br == 0 {
+		return d
+	}
+	return s
+}
+
+// Inv 用费马小定理返回 a^{-1}，a 为零时返回零
+func (m *Mont[E]) Inv(a E) E {
+	res := m.one
+	for i := len(m.exp) - 1; i >= 0; i-- {
+		for k := 63; k >= 0; k-- {
+			res = m.Mul(res, res)
+			if m.exp[i]>>uint(k)&1 == 1 {
+				res = m.Mul(res, a)
+			}
+		}
+	}
+	return res
+}
+
+// Rand 从 rnd 中取一个 [0, q) 中的均匀元素
+func (m *Mont[E]) Rand(rnd io.Reader) (E, error) {
+	v, err := rand.Int(rnd, m.mod)
+	if err != nil {
+		return m.Zero(), err
+	}
+	return m.FromBig(v), nil
+}
+
+// FromUint64 把 x mod q 转成 Montgomery 形式
+func (m *Mont[E]) FromUint64(x uint64) E {
+	var e E
+	e[0] = x
+	if len(e) == 1 {
+		e[0] = x % m.q[0]
+	}
+	return m.Mul(e, m.r2)
+}
+
+// FromBig 把 x mod q 转成 Montgomery 形式
+func (m *Mont[E]) FromBig(x *big.Int) E {
+	v := x
+	if x.Sign() < 0 || x.Cmp(m.mod) >= 0 {
+		v = new(big.Int).Mod(x, m.mod)
+	}
+	return m.Mul(toLimbs[E](v), m.r2)
+}
+
+// ToBig 返回 a 表示的 [0, q) 中的整数
+func (m *Mont[E]) ToBig(a E) *big.Int {
+	var one E
+	one[0] = 1
+	a = m.Mul(a, one)
+	var buf [32]byte
+	for i := 0; i < len(a); i++ {
+		for k := 0; k < 8; k++ {
+			buf[31-8*i-k] = byte(a[i] >> (8 * k))
+		}
+	}
+	return new(big.Int).SetBytes(buf[:])
+}
+
+// SetBytes 返回大端整数 b mod q，和 new(big.Int).SetBytes(b).Mod(q) 相同
+func (m *Mont[E]) SetBytes(b []byte) E {
+	acc := m.Zero()
+	for len(b) > 0 {
+		k := len(b) % 8
+		if k == 0 {
+			k = 8
+		}
+		var w uint64
+		for _, x := range b[:k] {
+			w = w<<8 | uint64(x)
+		}
+		b = b[k:]
+		acc = m.Add(m.Mul(acc, m.c64), m.FromUint64(w))
+	}
+	return acc
+}
@@ -0,0 +1,50 @@
+package field
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Ring64 is the ring Z_{2^64} with native uint64 arithmetic. Only odd
+// elements are invertible, so pivots have to be odd; Band.Solve handles this
+// by eliminating mod 2 and lifting (see TwoAdic), and succeeds exactly when
+// the rows mod 2 can be eliminated.
+type Ring64 struct{}
+
+func (Ring64) Zero() uint64 { return 0 }
+
+func (Ring64) One() uint64 { return 1 }
+
+func (Ring64) IsZero(a uint64) bool { return a == 0 }
+
+func (Ring64) Add(a, b uint64) uint64 { return a + b }
+
+func (Ring64) Sub(a, b uint64) uint64 { return a - b }
+
+func (Ring64) Mul(a, b uint64) uint64 { return a * b }
+
+// Inv 用 Newton 迭代求奇数的逆，偶数返回零
+func (Ring64) Inv(a uint64) uint64 {
+	if a&1 == 0 {
+		return 0
+	}
+	inv := a // a·a ≡ 1 mod 8
+	for i := 0; i < 5; i++ {
+		inv *= 2 - a*inv
+	}
+	return inv
+}
+
+func (Ring64) Rand(rnd io.Reader) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(rnd, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+func (Ring64) Bits() int { return 64 }
+
+func (Ring64) Bit(a uint64, t int) bool { return a>>uint(t)&1 == 1 }
+
+func (Ring64) Pow2(t int) uint64 { return 1 << uint(t) }
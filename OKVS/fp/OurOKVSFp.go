@@ -0,0 +1,312 @@
+package fp
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+	"sort"
+	"sync"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/OurOKVS/OKVS/internal/kernel"
+)
+
+// 定义System结构体
+type SystemFp struct {
+	Pos   int
+	Row   []*big.Int
+	Value *big.Int
+	Key   *big.Int
+}
+
+var zero = big.NewInt(0)
+
+type OKVSFp struct {
+	N    int //okvs存储的k-v长度
+	M    int //okvs的实际长度
+	W    int //随机块的长度
+	P    []*big.Int
+	Q    *big.Int
+	Seed []byte    // hash 种子，为空时等价于不加 key 的 blake2b
+	Tag  []byte    // 域分离标签
+	Rand io.Reader // 非空时用它随机填充非主元位置，使 OKVS 看起来是均匀随机的
+	// RandCoef 为 true 时带内第 j 个位置的系数是由 key 派生的随机域元素，
+	// 失败概率随域的大小下降；为 false 时系数是 hash2 的 0/1
+	RandCoef bool
+	Hash     okvs.HashMode // 起始位置的 hash 方式，零值是 Hash32，构造函数默认用 Hash64
+}
+
+// NewOKVSFp 构造模 q 的 OKVSFp，M = round(n*e)
+func NewOKVSFp(n int, q *big.Int, opts ...okvs.Option) (*OKVSFp, error) {
+	c := okvs.NewConfig(opts...)
+	m, err := c.Size(n)
+	if err != nil {
+		return nil, err
+	}
+	r := &OKVSFp{
+		N:        n,
+		M:        m,
+		W:        c.W,
+		P:        make([]*big.Int, m),
+		Q:        q,
+		Seed:     c.Seed,
+		Tag:      c.Tag,
+		Rand:     c.Rand,
+		RandCoef: c.RandCoef,
+		Hash:     c.Hash,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate 检查参数是否一致，q 必须是素数
+func (r *OKVSFp) Validate() error {
+	if err := common.CheckParams(r.N, r.M, r.W, r.M-r.W, len(r.P), r.Seed); err != nil {
+		return err
+	}
+	if r.Q == nil || !r.Q.ProbablyPrime(20) {
+		return common.ParamError("Q = %v must be prime", r.Q)
+	}
+	return r.Hash.Check(r.M - r.W)
+}
+
+type KVFp struct {
+	Key   *big.Int //key
+	Value *big.Int //value
+}
+
+// NewOkvsFp 随机生成 logq 位的素数 q 并构造 OKVSFp，参数非法时返回零值。
+// 需要错误信息或其他选项时请使用 NewOKVSFp。
+func NewOkvsFp(n, logq int, e float64) OKVSFp {
+	q, err := rand.Prime(rand.Reader, logq)
+	if err != nil {
+		return OKVSFp{}
+	}
+	okvs, err := NewOKVSFp(n, q, okvs.WithExpansion(e))
+	if err != nil {
+		return OKVSFp{}
+	}
+	return *okvs
+}
+
+func (r *OKVSFp) hash1(key *big.Int) int {
+	return r.Hash.Pos(r.Seed, r.Tag, key.Bytes(), r.M-r.W)
+}
+
+func (r *OKVSFp) hash2(key []byte) []byte {
+	bandsize := r.W / 8
+	hashBytes := okvs.HashWithSeed(bandsize, r.Seed, r.Tag, key)
+	return hashBytes
+}
+
+// coef 返回 key 在带内的 W 个随机系数。每个系数取比 Q 多 8 个字节的 hash 再模 Q，
+// 偏差可以忽略；返回的 big.Int 都是新分配的，消元可以原地修改
+func (r *OKVSFp) coef(key []byte) []*big.Int {
+	l := (r.Q.BitLen()+7)/8 + 8
+	buf := okvs.HashWithSeed(r.W*l, r.Seed, r.Tag, key)
+	row := make([]*big.Int, r.W)
+	for j := range row {
+		row[j] = new(big.Int).SetBytes(buf[j*l : (j+1)*l])
+		row[j].Mod(row[j], r.Q)
+	}
+	return row
+}
+
+func (r *OKVSFp) Init(kvs []KVFp) []SystemFp {
+	systems := make([]SystemFp, r.N)
+	for i := 0; i < r.N; i++ {
+		systems[i].Pos = r.hash1(kvs[i].Key)
+		if r.RandCoef {
+			systems[i].Row = r.coef(kvs[i].Key.Bytes())
+			systems[i].Value = kvs[i].Value
+			systems[i].Key = kvs[i].Key
+			continue
+		}
+		systems[i].Row = make([]*big.Int, r.W)
+		row := r.hash2(kvs[i].Key.Bytes())
+		for j := 0; j < r.W; j++ {
+			// 每个系数单独分配，消元会原地修改
+			if kernel.GetBit(row[j/8], j%8) {
+				systems[i].Row[j] = big.NewInt(1)
+			} else {
+				systems[i].Row[j] = new(big.Int)
+			}
+		}
+		//fmt.Println(systems[i].Row)
+		systems[i].Value = kvs[i].Value
+		systems[i].Key = kvs[i].Key
+	}
+	for i := 0; i < r.M; i++ {
+		r.P[i] = zero
+	}
+	//fmt.Println(r.P)
+	return systems
+}
+
+func (r *OKVSFp) Encode(kvs []KVFp) (*OKVSFp, error) {
+	n := r.N
+	if len(kvs) != n {
+		return nil, common.SizeMismatch(n, len(kvs))
+	}
+	if res, ok, err := r.encodeFast(kvs); ok {
+		return res, err
+	}
+	// 和 field.NewBand 的计数排序一样保持原来的顺序，两条路径得到相同的 P
+	systems := r.Init(kvs)
+	sort.SliceStable(systems, func(i, j int) bool {
+		return systems[i].Pos < systems[j].Pos
+	})
+	return r.encodeBig(systems)
+}
+
+// encodeBig 是 math/big 上的消元，任意素数模数都可以用：先把第 i 行乘以主元的逆使主元为 1，
+// 再从后面起始位置不超过主元列的行里消去主元列，回代时主元系数就是 1
+func (r *OKVSFp) encodeBig(systems []SystemFp) (*OKVSFp, error) {
+	n, w, q := r.N, r.W, r.Q
+	piv := make([]int, n)
+	t := new(big.Int)
+	for i := 0; i < n; i++ {
+		row := systems[i].Row
+		j := 0
+		for j < w && row[j].Sign() == 0 {
+			j++
+		}
+		if j == w {
+			return nil, &okvs.SingularError{Row: i, Key: systems[i].Key.Bytes()}
+		}
+		piv[i] = systems[i].Pos + j
+		inv := new(big.Int).ModInverse(row[j], q)
+		for s := j; s < w; s++ {
+			row[s].Mod(row[s].Mul(row[s], inv), q)
+		}
+		vi := new(big.Int).Mul(systems[i].Value, inv)
+		systems[i].Value = vi.Mod(vi, q)
+		for k := i + 1; k < n && systems[k].Pos <= piv[i]; k++ {
+			rk := systems[k].Row
+			d := systems[k].Pos - systems[i].Pos
+			c := rk[j-d]
+			if c.Sign() == 0 {
+				continue
+			}
+			c = new(big.Int).Set(c)
+			for s := j - d; s < w-d; s++ {
+				rk[s].Mod(rk[s].Sub(rk[s], t.Mul(c, row[s+d])), q)
+			}
+			vk := new(big.Int).Sub(systems[k].Value, t.Mul(c, vi))
+			systems[k].Value = vk.Mod(vk, q)
+		}
+	}
+	if r.Rand != nil {
+		if err := r.fillRandom(piv); err != nil {
+			return nil, err
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		pos := systems[i].Pos
+		res := new(big.Int).Set(systems[i].Value)
+		for s, c := range systems[i].Row {
+			if pos+s == piv[i] || c.Sign() == 0 {
+				continue
+			}
+			res.Sub(res, t.Mul(c, r.P[pos+s]))
+		}
+		r.P[piv[i]] = res.Mod(res, q)
+	}
+	return r, nil
+}
+
+// EncodeWithRetry 是换 Seed 重试的 Encode，见 common.EncodeWithRetry
+func (r *OKVSFp) EncodeWithRetry(kvs []KVFp, retries int) (*OKVSFp, error) {
+	err := common.EncodeWithRetry(retries, &r.Seed, func() error {
+		_, err := r.Encode(kvs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *OKVSFp) Decode(key *big.Int) *big.Int {
+	if r.RandCoef {
+		return r.decodeCoef(key)
+	}
+	pos := r.hash1(key)
+	row := r.hash2(key.Bytes())
+	res := big.NewInt(0)
+	index := 0
+	for j := 0; j < r.W; j++ {
+		if kernel.GetBit(row[j/8], j%8) {
+			//r.P[j] = r.P[j].ToWidth(32, bitarray.AlignRight)
+			index = j + pos
+			res = new(big.Int).Add(res, r.P[index])
+		}
+	}
+	res = new(big.Int).Mod(res, r.Q)
+	return res
+
+}
+
+// decodeCoef 计算随机系数和 P 的内积
+func (r *OKVSFp) decodeCoef(key *big.Int) *big.Int {
+	pos := r.hash1(key)
+	res := new(big.Int)
+	t := new(big.Int)
+	for j, c := range r.coef(key.Bytes()) {
+		res.Add(res, t.Mul(c, r.P[pos+j]))
+	}
+	return res.Mod(res, r.Q)
+}
+
+func (r *OKVSFp) ParDecode(kvs []KVFp) []*big.Int {
+	block := 4096
+	i := 0
+	end := i + block
+	res := make([]*big.Int, r.N)
+	var wg sync.WaitGroup
+	for {
+		if end >= r.N {
+			end = r.N
+		}
+		if i >= r.N {
+			break
+		}
+		wg.Add(1)
+		go func(i, end int) {
+			defer wg.Done()
+			for j := i; j < end; j++ {
+				res[j] = r.Decode(kvs[j].Key)
+			}
+		}(i, end)
+		i = i + block
+		end = end + block
+	}
+	wg.Wait()
+	return res
+}
+
+// Scalar 原地把 P 的全部 M 个位置乘以 k，之后 Decode 返回 k 倍的 value，返回新的 P。
+// 不想修改 r 时使用 ScalarMul。
+func (r *OKVSFp) Scalar(k *big.Int) []*big.Int {
+	r.P = r.ScalarMul(k).P
+	return r.P
+}
+
+func (r *OKVSFp) fillRandom(piv []int) error {
+	mask := common.PivotMask(len(r.P), piv)
+	for j := range r.P {
+		if mask[j] {
+			r.P[j] = zero
+			continue
+		}
+		v, err := rand.Int(r.Rand, r.Q)
+		if err != nil {
+			return err
+		}
+		r.P[j] = v
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package fp
+
+import (
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/OurOKVS/OKVS/field"
+)
+
+// fp 没有 Verify，Store 的 DecodeBatch 和 ParDecode 都应该和逐个 Decode 一致
+func TestDecodeBatch(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(3))
+	q := field.Mersenne61Q
+	keys := make([]*big.Int, homN)
+	raw := make([][]byte, homN)
+	for i := range keys {
+		keys[i] = new(big.Int).Rand(rng, q)
+		raw[i] = keys[i].Bytes()
+	}
+	r, vals := encodeFp(t, rng, q, keys)
+
+	kvs := make([]KVFp, len(keys))
+	for i := range kvs {
+		kvs[i] = KVFp{Key: keys[i], Value: vals[i]}
+	}
+	batch := r.AsStore().DecodeBatch(raw)
+	par := r.ParDecode(kvs)
+	for i, key := range keys {
+		want := r.Decode(key)
+		if want.Cmp(vals[i]) != 0 {
+			t.Fatalf("Decode[%d]: got %v, want %v", i, want, vals[i])
+		}
+		if batch[i].Cmp(want) != 0 {
+			t.Fatalf("DecodeBatch[%d]: got %v, want %v", i, batch[i], want)
+		}
+		if par[i].Cmp(want) != 0 {
+			t.Fatalf("ParDecode[%d]: got %v, want %v", i, par[i], want)
+		}
+	}
+}
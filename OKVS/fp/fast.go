@@ -0,0 +1,96 @@
+package fp
+
+import (
+	"math/big"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/field"
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/OurOKVS/OKVS/internal/kernel"
+)
+
+// arith 是定宽消元用到的域运算，由 field 包中的类型实现
+type arith[E comparable] interface {
+	field.Field[E]
+	FromBig(x *big.Int) E
+	ToBig(a E) *big.Int
+	SetBytes(b []byte) E
+}
+
+// encodeFast encodes with fixed-width limb arithmetic when Q allows it:
+// 2^61-1 uses field.Mersenne61, other odd primes up to 64, 128 and 256 bits
+// use Montgomery arithmetic on 1, 2 and 4 limbs. ok is false when no
+// fixed-width field fits Q and the caller has to fall back to math/big.
+func (r *OKVSFp) encodeFast(kvs []KVFp) (res *OKVSFp, ok bool, err error) {
+	bl := r.Q.BitLen()
+	switch {
+	case r.Q.Cmp(field.Mersenne61Q) == 0:
+		res, err = encodeWith[uint64](r, field.Mersenne61{}, kvs)
+		return res, true, err
+	case bl <= 64:
+		if f, ferr := field.NewMont[[1]uint64](r.Q); ferr == nil {
+			res, err = encodeWith[[1]uint64](r, f, kvs)
+			return res, true, err
+		}
+	case bl <= 128:
+		if f, ferr := field.NewMont[[2]uint64](r.Q); ferr == nil {
+			res, err = encodeWith[[2]uint64](r, f, kvs)
+			return res, true, err
+		}
+	case bl <= 256:
+		if f, ferr := field.NewMont[[4]uint64](r.Q); ferr == nil {
+			res, err = encodeWith[[4]uint64](r, f, kvs)
+			return res, true, err
+		}
+	}
+	return nil, false, nil
+}
+
+// encodeWith 和 encodeBig 做同样的消元，但行和 value 都是定宽的域元素，由 field.Band 求解
+func encodeWith[E comparable, F arith[E]](r *OKVSFp, f F, kvs []KVFp) (*OKVSFp, error) {
+	n, w := r.N, r.W
+	keys := make([][]byte, n)
+	pos := make([]int, n)
+	common.ParallelFor(n, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			keys[i] = kvs[i].Key.Bytes()
+			pos[i] = r.hash1(kvs[i].Key)
+		}
+	})
+	b := field.NewBand[E](f, r.M, w, pos)
+	l := (r.Q.BitLen()+7)/8 + 8
+	common.ParallelFor(n, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			k := b.Idx[i]
+			row := b.Row(i)
+			if r.RandCoef {
+				buf := okvs.HashWithSeed(w*l, r.Seed, r.Tag, keys[k])
+				for j := range row {
+					row[j] = f.SetBytes(buf[j*l : (j+1)*l])
+				}
+			} else {
+				h := r.hash2(keys[k])
+				for j := range row {
+					if kernel.GetBit(h[j/8], j%8) {
+						row[j] = f.One()
+					}
+				}
+			}
+			b.Vals[i] = f.FromBig(kvs[k].Value)
+		}
+	})
+
+	p, fail, err := b.Solve(r.Rand)
+	if err != nil {
+		return nil, err
+	}
+	if fail >= 0 {
+		return nil, &okvs.SingularError{Row: fail, Key: keys[b.Idx[fail]]}
+	}
+	common.ParallelFor(r.M, func(lo, hi int) {
+		for j := lo; j < hi; j++ {
+			r.P[j] = f.ToBig(p[j])
+		}
+	})
+	return r, nil
+}
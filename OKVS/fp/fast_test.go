@@ -0,0 +1,107 @@
+package fp
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	mrand "math/rand"
+	"sort"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/field"
+)
+
+// TestEncodeFastMatchesBig 检查定宽的消元和 math/big 的消元对同样的输入得到相同的 P
+func TestEncodeFastMatchesBig(t *testing.T) {
+	const n = 500
+	rng := mrand.New(mrand.NewSource(1))
+	p61 := big.NewInt(2305843009213693921) // 2^61 - 31
+	p127 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	for _, q := range []*big.Int{field.Mersenne61Q, p61, p127, field.P256Order, field.Curve25519Order} {
+		for _, rc := range []bool{false, true} {
+			opts := []okvs.Option{okvs.WithExpansion(1.3), okvs.WithBandWidth(128), okvs.WithSeed([]byte{byte(q.BitLen())}), okvs.WithRandomCoefficients(rc)}
+			fast, err := NewOKVSFp(n, q, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			slow, _ := NewOKVSFp(n, q, opts...)
+			kvs := make([]KVFp, n)
+			for i := range kvs {
+				kvs[i] = KVFp{Key: new(big.Int).Rand(rng, q), Value: new(big.Int).Rand(rng, q)}
+			}
+
+			_, ok, ferr := fast.encodeFast(kvs)
+			if !ok {
+				t.Fatalf("no fixed-width field for a %d-bit Q", q.BitLen())
+			}
+			systems := slow.Init(kvs)
+			sort.SliceStable(systems, func(i, j int) bool { return systems[i].Pos < systems[j].Pos })
+			_, serr := slow.encodeBig(systems)
+
+			var sing *okvs.SingularError
+			if ferr != nil || serr != nil {
+				if !errors.As(ferr, &sing) || !errors.As(serr, &sing) {
+					t.Fatalf("%d-bit Q, RandCoef %v: encodeFast error %v, encodeBig error %v", q.BitLen(), rc, ferr, serr)
+				}
+				continue
+			}
+			for j := range fast.P {
+				if fast.P[j].Cmp(slow.P[j]) != 0 {
+					t.Fatalf("%d-bit Q, RandCoef %v: P[%d] = %v from encodeFast, %v from encodeBig", q.BitLen(), rc, j, fast.P[j], slow.P[j])
+				}
+			}
+			for _, kv := range kvs {
+				if got := fast.Decode(kv.Key); got.Cmp(kv.Value) != 0 {
+					t.Fatalf("%d-bit Q, RandCoef %v: Decode = %v, want %v", q.BitLen(), rc, got, kv.Value)
+				}
+			}
+		}
+	}
+}
+
+// TestEncodeBigOrder 说明 Encode 为什么要用稳定排序：主元位置只取决于行张成的空间，
+// 所以成功时 P 和行的顺序无关；但系统奇异时，报告的是第一个被消成 0 的行，
+// 起始位置相同的行换个顺序，报告的 key 就变了。保持 kvs 原来的顺序，
+// encodeBig 的 SingularError 才和按计数排序的 encodeFast 相同
+func TestEncodeBigOrder(t *testing.T) {
+	const n = 200
+	rng := mrand.New(mrand.NewSource(30))
+	q := field.Mersenne61Q
+	// W = 8 的行很容易线性相关
+	opts := testOpts(okvs.WithBandWidth(8))
+	kvs := make([]KVFp, n)
+	for i := range kvs {
+		kvs[i] = KVFp{Key: new(big.Int).Rand(rng, q), Value: new(big.Int).Rand(rng, q)}
+	}
+	fast, _ := NewOKVSFp(n, q, opts...)
+	_, ok, err := fast.encodeFast(kvs)
+	var want *okvs.SingularError
+	if !ok || !errors.As(err, &want) {
+		t.Fatalf("encodeFast: ok = %v, err = %v, want a SingularError", ok, err)
+	}
+
+	encode := func(less func(a, b SystemFp) bool) *okvs.SingularError {
+		r, _ := NewOKVSFp(n, q, opts...)
+		systems := r.Init(kvs)
+		sort.SliceStable(systems, func(i, j int) bool { return less(systems[i], systems[j]) })
+		_, err := r.encodeBig(systems)
+		var sing *okvs.SingularError
+		if !errors.As(err, &sing) {
+			t.Fatalf("encodeBig: got %v, want a SingularError", err)
+		}
+		return sing
+	}
+
+	got := encode(func(a, b SystemFp) bool { return a.Pos < b.Pos })
+	if got.Row != want.Row || !bytes.Equal(got.Key, want.Key) {
+		t.Fatalf("encodeBig in the original order of ties: row %d key %x, encodeFast: row %d key %x", got.Row, got.Key, want.Row, want.Key)
+	}
+	// 相同 Pos 的行按 key 倒序，相当于 sort.Slice 可能给出的另一种顺序
+	rev := encode(func(a, b SystemFp) bool {
+		return a.Pos < b.Pos || a.Pos == b.Pos && a.Key.Cmp(b.Key) > 0
+	})
+	if bytes.Equal(rev.Key, want.Key) {
+		t.Fatal("reordering rows with equal Pos reported the same key; the fixture has no effective ties")
+	}
+}
@@ -0,0 +1,84 @@
+package fp
+
+import (
+	"io"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// MaxModulusBits 是容器里 Q 的最大位数，更大的 Q 在检查素性之前就被拒绝
+const MaxModulusBits = 4096
+
+func init() {
+	okvs.RegisterLoader(okvs.VariantOKVSFp, func(h *okvs.Header, payload []byte) (okvs.Store, error) {
+		r, err := okvsfpFrom(h, payload)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+// WriteTo writes r as an okvs container. Q goes into the header and every
+// slot of P takes the byte length of Q, big-endian. Rand is not stored.
+func (r *OKVSFp) WriteTo(w io.Writer) (int64, error) {
+	width := (r.Q.BitLen() + 7) / 8
+	h := &okvs.Header{
+		Variant:    okvs.VariantOKVSFp,
+		ValueWidth: width,
+		N:          r.N,
+		M:          r.M,
+		W:          r.W,
+		R:          r.M - r.W,
+		Seed:       r.Seed,
+		Tag:        r.Tag,
+		Modulus:    r.Q,
+	}
+	h.Flags |= okvs.HashFlag(r.Hash)
+	if r.RandCoef {
+		h.Flags |= okvs.FlagRandCoef
+	}
+	return okvs.WriteContainer(w, h, common.PutBigs(r.P, width))
+}
+
+// ReadOKVSFp 读取 OKVSFp.WriteTo 写的容器
+func ReadOKVSFp(rd io.Reader) (*OKVSFp, error) {
+	h, payload, err := okvs.ReadContainer(rd)
+	if err != nil {
+		return nil, err
+	}
+	return okvsfpFrom(h, payload)
+}
+
+func okvsfpFrom(h *okvs.Header, payload []byte) (*OKVSFp, error) {
+	if h.Modulus == nil || h.Modulus.BitLen() > MaxModulusBits {
+		return nil, common.FormatError("modulus must be present and at most %d bits", MaxModulusBits)
+	}
+	if err := h.Expect(okvs.VariantOKVSFp, (h.Modulus.BitLen()+7)/8, payload); err != nil {
+		return nil, err
+	}
+	if h.R != h.M-h.W {
+		return nil, common.ParamError("R = %d, want M - W = %d", h.R, h.M-h.W)
+	}
+	r := &OKVSFp{
+		N:        h.N,
+		M:        h.M,
+		W:        h.W,
+		P:        common.Bigs(payload, h.M, h.ValueWidth),
+		Q:        h.Modulus,
+		Seed:     h.Seed,
+		Tag:      h.Tag,
+		RandCoef: h.Flags&okvs.FlagRandCoef != 0,
+		Hash:     h.HashMode(),
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	for j, v := range r.P {
+		if v.Cmp(r.Q) >= 0 {
+			return nil, common.ParamError("P[%d] is not reduced mod Q", j)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,94 @@
+package fp
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// Decode is linear in P, so combining the P arrays of structures that share
+// N, M, W, Q, Seed, Tag, RandCoef and Hash position by position combines the
+// decoded values the same way: Decode(a.Add(b)) = Decode(a) + Decode(b) mod Q
+// for every key, and likewise for the other operations below. None of them
+// modifies its operands.
+
+// Compatible 检查 o 和 r 是否可以逐位置组合，起始位置的 hash 方式也必须相同，范围 M - W 由 M 和 W 决定
+func (r *OKVSFp) Compatible(o *OKVSFp) error {
+	switch {
+	case r.N != o.N || r.M != o.M || r.W != o.W || len(r.P) != len(o.P):
+		return fmt.Errorf("%w: N, M, W = %d, %d, %d and %d, %d, %d", okvs.ErrIncompatible, r.N, r.M, r.W, o.N, o.M, o.W)
+	case r.Q.Cmp(o.Q) != 0:
+		return fmt.Errorf("%w: Q = %v and %v", okvs.ErrIncompatible, r.Q, o.Q)
+	case !bytes.Equal(r.Seed, o.Seed) || !bytes.Equal(r.Tag, o.Tag):
+		return fmt.Errorf("%w: different seed or tag", okvs.ErrIncompatible)
+	case r.RandCoef != o.RandCoef:
+		return fmt.Errorf("%w: RandCoef = %v and %v", okvs.ErrIncompatible, r.RandCoef, o.RandCoef)
+	case r.Hash != o.Hash:
+		return fmt.Errorf("%w: Hash = %v and %v", okvs.ErrIncompatible, r.Hash, o.Hash)
+	}
+	return nil
+}
+
+// entry 把还没有编码的 nil 位置当成 0
+func entry(p *big.Int) *big.Int {
+	if p == nil {
+		return zero
+	}
+	return p
+}
+
+// with 返回参数和 r 相同、P 由 f 逐位置算出的新 OKVSFp
+func (r *OKVSFp) with(f func(j int, t *big.Int) *big.Int) *OKVSFp {
+	res := *r
+	res.P = make([]*big.Int, len(r.P))
+	common.ParallelFor(len(r.P), func(lo, hi int) {
+		for j := lo; j < hi; j++ {
+			t := f(j, new(big.Int))
+			res.P[j] = t.Mod(t, r.Q)
+		}
+	})
+	return &res
+}
+
+// ScalarMul 返回 P 乘以 k 的新 OKVSFp，Decode 得到 k·value
+func (r *OKVSFp) ScalarMul(k *big.Int) *OKVSFp {
+	return r.with(func(j int, t *big.Int) *big.Int { return t.Mul(entry(r.P[j]), k) })
+}
+
+// Add 返回 Decode 得到两者之和的 OKVSFp
+func (r *OKVSFp) Add(o *OKVSFp) (*OKVSFp, error) {
+	if err := r.Compatible(o); err != nil {
+		return nil, err
+	}
+	return r.with(func(j int, t *big.Int) *big.Int { return t.Add(entry(r.P[j]), entry(o.P[j])) }), nil
+}
+
+// Sub 返回 Decode 得到两者之差的 OKVSFp
+func (r *OKVSFp) Sub(o *OKVSFp) (*OKVSFp, error) {
+	if err := r.Compatible(o); err != nil {
+		return nil, err
+	}
+	return r.with(func(j int, t *big.Int) *big.Int { return t.Sub(entry(r.P[j]), entry(o.P[j])) }), nil
+}
+
+// LinearCombination 返回 Decode 得到 Σ coeffs[i]·Decode_i 的 OKVSFp，vs 至少要有一个
+func LinearCombination(coeffs []*big.Int, vs []*OKVSFp) (*OKVSFp, error) {
+	if len(vs) == 0 || len(coeffs) != len(vs) {
+		return nil, fmt.Errorf("%w: %d coefficients for %d structures", okvs.ErrIncompatible, len(coeffs), len(vs))
+	}
+	for _, v := range vs[1:] {
+		if err := vs[0].Compatible(v); err != nil {
+			return nil, err
+		}
+	}
+	return vs[0].with(func(j int, t *big.Int) *big.Int {
+		u := new(big.Int)
+		for i, v := range vs {
+			t.Add(t, u.Mul(coeffs[i], entry(v.P[j])))
+		}
+		return t
+	}), nil
+}
@@ -0,0 +1,124 @@
+package fp
+
+import (
+	"errors"
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/field"
+)
+
+const homN = 500
+
+// encodeFp 用随机的 value 和随机填充编码 keys，返回 OKVSFp 和 value
+func encodeFp(t *testing.T, rng *mrand.Rand, q *big.Int, keys []*big.Int, opts ...okvs.Option) (*OKVSFp, []*big.Int) {
+	t.Helper()
+	r, err := NewOKVSFp(len(keys), q, testOpts(append(opts, okvs.WithRand(rng))...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kvs := make([]KVFp, len(keys))
+	vals := make([]*big.Int, len(keys))
+	for i := range kvs {
+		vals[i] = new(big.Int).Rand(rng, q)
+		kvs[i] = KVFp{Key: keys[i], Value: vals[i]}
+	}
+	if _, err := r.Encode(kvs); err != nil {
+		t.Fatal(err)
+	}
+	return r, vals
+}
+
+func TestHomomorphic(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(1))
+	for _, q := range []*big.Int{field.Mersenne61Q, field.P256Order} {
+		for _, rc := range []bool{false, true} {
+			keys := make([]*big.Int, homN)
+			for i := range keys {
+				keys[i] = new(big.Int).Rand(rng, q)
+			}
+			a, va := encodeFp(t, rng, q, keys, okvs.WithRandomCoefficients(rc))
+			b, vb := encodeFp(t, rng, q, keys, okvs.WithRandomCoefficients(rc))
+			k := new(big.Int).Rand(rng, q)
+			l := big.NewInt(-7)
+
+			sum, err := a.Add(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff, err := a.Sub(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lin, err := LinearCombination([]*big.Int{k, l}, []*OKVSFp{a, b})
+			if err != nil {
+				t.Fatal(err)
+			}
+			scaled := a.ScalarMul(k)
+			mod := func(x *big.Int) *big.Int { return x.Mod(x, q) }
+			for i, key := range keys {
+				for _, c := range []struct {
+					op        string
+					got, want *big.Int
+				}{
+					{"Add", sum.Decode(key), mod(new(big.Int).Add(va[i], vb[i]))},
+					{"Sub", diff.Decode(key), mod(new(big.Int).Sub(va[i], vb[i]))},
+					{"ScalarMul", scaled.Decode(key), mod(new(big.Int).Mul(k, va[i]))},
+					{"LinearCombination", lin.Decode(key), mod(new(big.Int).Add(new(big.Int).Mul(k, va[i]), new(big.Int).Mul(l, vb[i])))},
+					{"operand", a.Decode(key), va[i]},
+				} {
+					if c.got.Cmp(c.want) != 0 {
+						t.Fatalf("%d-bit Q, RandCoef %v: %s decodes to %v, want %v", q.BitLen(), rc, c.op, c.got, c.want)
+					}
+				}
+			}
+
+			// Scalar 原地修改 a
+			a.Scalar(k)
+			for i, key := range keys {
+				if got, want := a.Decode(key), mod(new(big.Int).Mul(k, va[i])); got.Cmp(want) != 0 {
+					t.Fatalf("%d-bit Q, RandCoef %v: Scalar decodes to %v, want %v", q.BitLen(), rc, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestHomomorphicIncompatible(t *testing.T) {
+	q := field.Mersenne61Q
+	a, err := NewOKVSFp(homN, q, testOpts()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mk := func(n int, q *big.Int, opts ...okvs.Option) *OKVSFp {
+		r, err := NewOKVSFp(n, q, testOpts(opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	for name, o := range map[string]*OKVSFp{
+		"N":        mk(homN+1, q),
+		"W":        mk(homN, q, okvs.WithBandWidth(136)),
+		"Q":        mk(homN, field.P256Order),
+		"seed":     mk(homN, q, okvs.WithSeed([]byte("another seed"))),
+		"tag":      mk(homN, q, okvs.WithTag([]byte("tag"))),
+		"RandCoef": mk(homN, q, okvs.WithRandomCoefficients(true)),
+		"Hash":     mk(homN, q, okvs.WithHashMode(okvs.Hash32)),
+	} {
+		if _, err := a.Add(o); !errors.Is(err, okvs.ErrIncompatible) {
+			t.Errorf("Add with a different %s: got %v, want ErrIncompatible", name, err)
+		}
+		if _, err := a.Sub(o); !errors.Is(err, okvs.ErrIncompatible) {
+			t.Errorf("Sub with a different %s: got %v, want ErrIncompatible", name, err)
+		}
+		if _, err := LinearCombination([]*big.Int{big.NewInt(1), big.NewInt(1)}, []*OKVSFp{a, o}); !errors.Is(err, okvs.ErrIncompatible) {
+			t.Errorf("LinearCombination with a different %s: got %v, want ErrIncompatible", name, err)
+		}
+	}
+	if _, err := LinearCombination([]*big.Int{big.NewInt(1)}, []*OKVSFp{a, a}); !errors.Is(err, okvs.ErrIncompatible) {
+		t.Errorf("LinearCombination with too few coefficients: got %v, want ErrIncompatible", err)
+	}
+}
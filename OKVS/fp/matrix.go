@@ -0,0 +1,33 @@
+package fp
+
+import (
+	"math/big"
+
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/OurOKVS/OKVS/internal/kernel"
+	"github.com/OurOKVS/OKVS/linear"
+)
+
+// Matrix 返回 keys 对应的解码矩阵，a.Mul(r.P)[i] == r.Decode(keys[i])。
+// 矩阵中的系数可能共用同一个 big.Int，只能读不能改。
+func (r *OKVSFp) Matrix(keys []*big.Int) *linear.Fp {
+	a := &linear.Fp{N: len(keys), M: r.M, W: r.W, Q: r.Q, Pos: make([]int, len(keys)), Coef: make([][]*big.Int, len(keys))}
+	one := big.NewInt(1)
+	common.ParallelFor(len(keys), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			a.Pos[i] = r.hash1(keys[i])
+			if r.RandCoef {
+				a.Coef[i] = r.coef(keys[i].Bytes())
+				continue
+			}
+			row := r.hash2(keys[i].Bytes())
+			a.Coef[i] = make([]*big.Int, r.W)
+			for j := 0; j < r.W; j++ {
+				if kernel.GetBit(row[j/8], j%8) {
+					a.Coef[i][j] = one
+				}
+			}
+		}
+	})
+	return a
+}
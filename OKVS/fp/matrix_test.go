@@ -0,0 +1,41 @@
+package fp
+
+import (
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/field"
+)
+
+var testSeed = []byte("fp test seed")
+
+// testOpts 是 fp 的测试共用的参数，扩张率 1.3、W = 128 时编码几乎不会失败
+func testOpts(opts ...okvs.Option) []okvs.Option {
+	return append([]okvs.Option{okvs.WithExpansion(1.3), okvs.WithBandWidth(128), okvs.WithSeed(testSeed)}, opts...)
+}
+
+func TestMatrix(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(4))
+	q := field.Mersenne61Q
+	for _, rc := range []bool{false, true} {
+		r, err := NewOKVSFp(200, q, testOpts(okvs.WithRandomCoefficients(rc))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range r.P {
+			r.P[j] = new(big.Int).Rand(rng, q)
+		}
+		keys := make([]*big.Int, r.N)
+		for i := range keys {
+			keys[i] = new(big.Int).Rand(rng, q)
+		}
+		got := r.Matrix(keys).Mul(r.P)
+		for i, key := range keys {
+			if want := r.Decode(key); got[i].Cmp(want) != 0 {
+				t.Fatalf("RandCoef = %v: Matrix.Mul(P)[%d] = %v, want %v", rc, i, got[i], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package fp
+
+import (
+	"math/big"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// fp 后端的模数通过 okvs.WithModulus 传入
+func init() {
+	okvs.Register("fp", func(n int, opts ...okvs.Option) (okvs.Store, error) {
+		c := okvs.NewConfig(opts...)
+		r, err := NewOKVSFp(n, c.Modulus, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+type storeOKVSFp struct{ r *OKVSFp }
+
+// AsStore 把 OKVSFp 包装成 Store，key 按大端字节解释为整数
+func (r *OKVSFp) AsStore() okvs.Store { return storeOKVSFp{r} }
+
+func (s storeOKVSFp) Encode(pairs []okvs.Pair) error {
+	kvs := make([]KVFp, len(pairs))
+	for i := range pairs {
+		if pairs[i].Value == nil {
+			return common.NilValue(i)
+		}
+		kvs[i] = KVFp{Key: new(big.Int).SetBytes(pairs[i].Key), Value: pairs[i].Value}
+	}
+	_, err := s.r.Encode(kvs)
+	return err
+}
+
+func (s storeOKVSFp) Decode(key []byte) *big.Int {
+	return s.r.Decode(new(big.Int).SetBytes(key))
+}
+
+func (s storeOKVSFp) DecodeBatch(keys [][]byte) []*big.Int { return common.DecodeBatch(keys, s.Decode) }
+
+func (s storeOKVSFp) Size() int { return s.r.M * ((s.r.Q.BitLen() + 7) / 8) }
+
+func (s storeOKVSFp) Params() okvs.Params {
+	return okvs.Params{N: s.r.N, M: s.r.M, W: s.r.W, R: s.r.M - s.r.W}
+}
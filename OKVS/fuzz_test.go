@@ -0,0 +1,201 @@
+package okvs_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/bigint"
+	"github.com/OurOKVS/OKVS/bpsy23"
+	"github.com/OurOKVS/OKVS/buffer"
+	"github.com/OurOKVS/OKVS/ecdlp"
+	"github.com/OurOKVS/OKVS/field"
+	"github.com/OurOKVS/OKVS/fp"
+	"github.com/OurOKVS/OKVS/ourf2"
+)
+
+// 模糊测试的种子都是小的合法结构，P 全为 0 就够了
+const fuzzN = 16
+
+var fuzzOpts = []okvs.Option{okvs.WithExpansion(8), okvs.WithBandWidth(64), okvs.WithSeed([]byte("seed")), okvs.WithTag([]byte("tag"))}
+
+var fuzzKey = bytes.Repeat([]byte{0xa5}, 64)
+
+func containers(f *testing.F) [][]byte {
+	bk, _ := ourf2.NewOKVSBK(fuzzN, append(fuzzOpts, okvs.WithBitPositions(true))...)
+	bw, _ := ourf2.NewOKVSBKW(fuzzN, append(fuzzOpts, okvs.WithValueSize(11))...)
+	ec, _ := ecdlp.NewOKVSECC(fuzzN, fuzzOpts...)
+	bp, _ := bpsy23.NewOKVS(fuzzN, fuzzOpts...)
+	bb, _ := bigint.NewOKVSB(fuzzN, fuzzOpts...)
+	bb.P[3] = big.NewInt(1 << 40)
+	bf, _ := buffer.NewOKVSBF(fuzzN, fuzzOpts...)
+	fq, _ := fp.NewOKVSFp(fuzzN, field.Mersenne61Q, append(fuzzOpts, okvs.WithRandomCoefficients(true))...)
+	var res [][]byte
+	for _, w := range []io.WriterTo{bk, bw, ec, bp, bb, bf, fq} {
+		var buf bytes.Buffer
+		if _, err := w.WriteTo(&buf); err != nil {
+			f.Fatal(err)
+		}
+		res = append(res, buf.Bytes())
+	}
+	return res
+}
+
+// 伪造的头：VW = 16、M = 2^60-1，payload 长度 2^64-16 加上摘要会回绕
+const (
+	overflowWidth = 16
+	overflowM     = 1<<60 - 1
+)
+
+func overflowContainer() []byte {
+	le := binary.LittleEndian
+	b := []byte(okvs.Magic)
+	b = le.AppendUint16(b, okvs.FormatVersion)
+	b = append(b, byte(okvs.VariantOKVSBK), 0)
+	b = le.AppendUint32(b, overflowWidth)
+	b = le.AppendUint64(b, 1)
+	b = le.AppendUint64(b, overflowM)
+	b = le.AppendUint32(b, 8)
+	b = le.AppendUint64(b, 1)
+	b = append(b, 0, 0, 0, 0, 0, 0)
+	b = le.AppendUint64(b, overflowWidth*overflowM)
+	b = append(b, make([]byte, -len(b)&(okvs.PayloadAlign-1))...)
+	return append(b, make([]byte, 16)...)
+}
+
+// onlyReader 隐藏 Len，让 ReadContainer 走长度未知的路径
+type onlyReader struct{ io.Reader }
+
+func FuzzLoad(f *testing.F) {
+	for _, c := range containers(f) {
+		f.Add(c)
+	}
+	f.Add(overflowContainer())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if h, payload, err := okvs.ParseContainer(data); err == nil {
+			if err := h.Check(uint64(len(payload))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, r := range []io.Reader{bytes.NewReader(data), onlyReader{bytes.NewReader(data)}} {
+			s, err := okvs.Load(r)
+			if err != nil {
+				if s != nil {
+					t.Fatal("Load returned a store with an error")
+				}
+				continue
+			}
+			p := s.Params()
+			if p.N <= 0 || p.N > p.M || p.R+p.W > p.M {
+				t.Fatalf("Load accepted inconsistent parameters %+v", p)
+			}
+			s.Decode(fuzzKey)
+		}
+	})
+}
+
+func FuzzDeserializeLegacy(f *testing.F) {
+	dir := f.TempDir()
+	bk, _ := ourf2.NewOKVSBK(fuzzN, append(fuzzOpts, okvs.WithBitPositions(true))...)
+	ec, _ := ecdlp.NewOKVSECC(fuzzN, fuzzOpts...)
+	for i, write := range []func(string) error{
+		func(name string) error { return ourf2.SerializeOKVSBK(name, *bk) },
+		func(name string) error { return ecdlp.SerializeOKVSECC(name, *ec) },
+	} {
+		name := filepath.Join(dir, string(rune('a'+i)))
+		if err := write(name); err != nil {
+			f.Fatal(err)
+		}
+		b, err := os.ReadFile(name)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		name := filepath.Join(t.TempDir(), "okvs")
+		if err := os.WriteFile(name, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if r, err := ourf2.DeserializeOKVSBK(name); err == nil {
+			r.Decode(fuzzKey)
+		}
+		if r, err := ecdlp.DeserializeOKVSECC(name); err == nil {
+			r.Decode(fuzzKey)
+		}
+	})
+}
+
+func FuzzReadSolver(f *testing.F) {
+	r, _ := ourf2.NewOKVSBK(fuzzN, fuzzOpts...)
+	keys := make([][]byte, fuzzN)
+	for i := range keys {
+		keys[i] = []byte{byte(i), 1, 2, 3}
+	}
+	sv, err := r.Prepare(keys)
+	if err == nil {
+		var buf bytes.Buffer
+		if _, err := sv.WriteTo(&buf); err != nil {
+			f.Fatal(err)
+		}
+		f.Add(buf.Bytes())
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		sv, err := ourf2.ReadSolver(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		if _, err := sv.Encode(make([]uint32, sv.N)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// FuzzLoadHeader 用合法的摘要包装任意的头字段，检查摘要之后的一致性检查
+func FuzzLoadHeader(f *testing.F) {
+	for _, c := range containers(f) {
+		h, payload, err := okvs.ReadContainer(bytes.NewReader(c))
+		if err != nil {
+			f.Fatal(err)
+		}
+		var mod []byte
+		if h.Modulus != nil {
+			mod = h.Modulus.Bytes()
+		}
+		f.Add(uint8(h.Variant), h.Flags, uint32(h.ValueWidth), uint64(h.N), uint64(h.M), uint32(h.W), uint64(h.R), h.Seed, mod, payload)
+	}
+	f.Add(uint8(okvs.VariantOKVSBK), uint8(0), uint32(overflowWidth), uint64(1), uint64(overflowM), uint32(8), uint64(1), []byte(nil), []byte(nil), make([]byte, 16))
+	f.Fuzz(func(t *testing.T, variant, flags uint8, width uint32, n, m uint64, w uint32, r uint64, seed, mod, payload []byte) {
+		h := &okvs.Header{
+			Variant:    okvs.Variant(variant),
+			Flags:      flags,
+			ValueWidth: int(width),
+			N:          int(n),
+			M:          int(m),
+			W:          int(w),
+			R:          int(r),
+			Seed:       seed,
+		}
+		if len(mod) > 0 {
+			h.Modulus = new(big.Int).SetBytes(mod)
+		}
+		var buf bytes.Buffer
+		if _, err := okvs.WriteContainer(&buf, h, payload); err != nil {
+			return
+		}
+		s, err := okvs.Load(&buf)
+		if err != nil {
+			return
+		}
+		p := s.Params()
+		if p.N <= 0 || p.N > p.M || p.R+p.W > p.M {
+			t.Fatalf("Load accepted inconsistent parameters %+v", p)
+		}
+		s.Decode(fuzzKey)
+	})
+}
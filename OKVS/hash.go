@@ -0,0 +1,119 @@
+package okvs
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// SeedSize 是 EncodeWithRetry 生成的 seed 长度
+const SeedSize = 32
+
+// ErrSeedSize 表示 seed 超过了 blake2b 允许的 key 长度
+var ErrSeedSize = errors.New("okvs: seed must be at most 64 bytes")
+
+// HashToFixedSize 用不加 key 的 blake2b 把 key 哈希成 bytesize 个字节
+func HashToFixedSize(bytesize int, key []byte) []byte {
+	return HashWithSeed(bytesize, nil, nil, key)
+}
+
+// HashWithSeed is HashToFixedSize keyed with seed, with tag written before
+// the key for domain separation. With an empty seed and tag it returns
+// exactly what HashToFixedSize returns.
+func HashWithSeed(bytesize int, seed, tag, key []byte) []byte {
+	if bytesize <= 64 {
+		hash, _ := blake2b.New(bytesize, seed)
+		hash.Write(tag)
+		hash.Write(key)
+		return hash.Sum(nil)
+	}
+
+	// If bytesize > 64, generate multiple hashes and concatenate them
+	numHashes := (bytesize + 63) / 64 // Calculate the number of 64-byte chunks needed
+	hashResult := make([]byte, 0, numHashes*64)
+	for i := 0; i < numHashes; i++ {
+		hash, _ := blake2b.New(64, seed)
+		hash.Write(tag)
+		hash.Write(key)
+		// Add different data to each hash to ensure uniqueness
+		hash.Write([]byte(fmt.Sprintf("%d", i)))
+		hashResult = append(hashResult, hash.Sum(nil)...)
+	}
+	return hashResult[:bytesize]
+}
+
+// NewSeed 从 crypto/rand 生成一个新的 seed
+func NewSeed() ([]byte, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// HashMode 选择 key 的起始位置怎样从 hash 得到。零值是 Hash32，
+// 和旧代码一样，所以手写的结构体字面量的位置不变；构造函数默认用 Hash64
+type HashMode uint8
+
+const (
+	// Hash32 是旧的 4 字节 hash 对 n 取模，有取模偏差，n 不能超过 2^32
+	Hash32 HashMode = iota
+	// Hash64 takes 8 bytes of hash and maps them to [0, n) by multiply-shift,
+	// redrawing in the rare case that would be biased, so every position is
+	// equally likely and n may exceed 2^32. NewConfig selects it by default.
+	Hash64
+)
+
+func (m HashMode) String() string {
+	switch m {
+	case Hash64:
+		return "Hash64"
+	case Hash32:
+		return "Hash32"
+	}
+	return fmt.Sprintf("HashMode(%d)", uint8(m))
+}
+
+// Check reports whether m is a known mode that can address a range of n.
+func (m HashMode) Check(n int) error {
+	switch {
+	case m > Hash64:
+		return paramError("unknown hash mode %v", m)
+	case m == Hash32 && uint64(n) > math.MaxUint32:
+		return paramError("hash range %d needs Hash64", n)
+	}
+	return nil
+}
+
+// Pos hashes key with seed and tag and returns a position in [0, n).
+func (m HashMode) Pos(seed, tag, key []byte, n int) int {
+	if m == Hash32 {
+		return int(binary.BigEndian.Uint32(HashWithSeed(4, seed, tag, key))) % n
+	}
+	return int(Reduce(binary.BigEndian.Uint64(HashWithSeed(8, seed, tag, key)), uint64(n), func(k int) uint64 {
+		// 第 k 次重抽取更长的 hash 的最后 8 个字节，blake2b 的输出长度不同结果就不相关
+		h := HashWithSeed(8*(k+1), seed, tag, key)
+		return binary.BigEndian.Uint64(h[8*k:])
+	}))
+}
+
+// Reduce maps the uniform 64-bit x to [0, n) without bias, using Lemire's
+// multiply-shift with rejection: the result is the high word of x*n, and
+// the low word tells the few x that would overrepresent some results, with
+// probability below n/2^64. For those it asks next(1), next(2), ... for
+// fresh uniform words until one is accepted.
+func Reduce(x, n uint64, next func(k int) uint64) uint64 {
+	hi, lo := bits.Mul64(x, n)
+	if lo < n {
+		t := -n % n
+		for k := 1; lo < t; k++ {
+			hi, lo = bits.Mul64(next(k), n)
+		}
+	}
+	return hi
+}
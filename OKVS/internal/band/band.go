@@ -0,0 +1,232 @@
+// Package band solves the banded GF(2) systems behind the binary-field OKVS
+// variants. Rows are packed into uint64 words and band start positions are
+// bit-granular, so a row operation is a few shifted word XORs and the pivot
+// search is a trailing-zero count.
+//
+// Bit j of a packed row is column Pos+j, stored in word j/64 at bit j%64.
+// Values are packed the same way, VW words per row, so any value width is
+// handled by the same row operations.
+package band
+
+import (
+	"math/bits"
+)
+
+// Solver 保存按 Pos 排好序的 N 行
+type Solver struct {
+	N, M, W int
+	RW      int      // 每一行的 word 数
+	VW      int      // 每个 value 的 word 数
+	Pos     []int    // 第 i 行的起始列
+	Idx     []int    // 第 i 行对应的原始下标
+	Rows    []uint64 // N*RW
+	Vals    []uint64 // N*VW
+	Record  bool     // 为 true 时 Eliminate 把每次行操作记到 Ops
+	Ops     []int    // 每两个一组 (k, i)，表示第 k 行异或上第 i 行
+}
+
+// New sorts the rows by start position and allocates the packed storage.
+// pos[i] is the start column of the original row i and must lie in
+// [0, m-w].
+func New(m, w, vw int, pos []int) *Solver {
+	n := len(pos)
+	s := &Solver{
+		N:    n,
+		M:    m,
+		W:    w,
+		RW:   (w + 63) / 64,
+		VW:   vw,
+		Pos:  make([]int, n),
+		Idx:  make([]int, n),
+		Vals: make([]uint64, n*vw),
+	}
+	s.Rows = make([]uint64, n*s.RW)
+
+	// 计数排序，Pos 的范围只有 m
+	count := make([]int, m+1)
+	for _, p := range pos {
+		count[p+1]++
+	}
+	for c := 1; c <= m; c++ {
+		count[c] += count[c-1]
+	}
+	for i, p := range pos {
+		k := count[p]
+		count[p]++
+		s.Pos[k] = p
+		s.Idx[k] = i
+	}
+	return s
+}
+
+// Adopt builds a Solver on rows and values the caller has already packed in
+// their original order, RW and vw words per row, and sorts them by start
+// position in place instead of copying them into new storage. pos becomes
+// Pos and is sorted along with them.
+func Adopt(m, w, vw int, pos []int, rows, vals []uint64) *Solver {
+	n := len(pos)
+	s := &Solver{N: n, M: m, W: w, RW: (w + 63) / 64, VW: vw, Pos: pos, Idx: make([]int, n), Rows: rows, Vals: vals}
+
+	// 计数排序只算出每一行的目标位置，再沿着置换的环原地交换
+	dst := make([]int, n)
+	count := make([]int, m+1)
+	for _, p := range pos {
+		count[p+1]++
+	}
+	for c := 1; c <= m; c++ {
+		count[c] += count[c-1]
+	}
+	for i, p := range pos {
+		dst[i] = count[p]
+		s.Idx[dst[i]] = i
+		count[p]++
+	}
+	for i := 0; i < n; i++ {
+		for dst[i] != i {
+			k := dst[i]
+			swapWords(s.Row(i), s.Row(k))
+			swapWords(s.Val(i), s.Val(k))
+			pos[i], pos[k] = pos[k], pos[i]
+			dst[i], dst[k] = dst[k], dst[i]
+		}
+	}
+	return s
+}
+
+func swapWords(a, b []uint64) {
+	for t := range a {
+		a[t], b[t] = b[t], a[t]
+	}
+}
+
+// Row returns the packed band of sorted row i.
+func (s *Solver) Row(i int) []uint64 { return s.Rows[i*s.RW : (i+1)*s.RW] }
+
+// Val returns the packed value of sorted row i.
+func (s *Solver) Val(i int) []uint64 { return s.Vals[i*s.VW : (i+1)*s.VW] }
+
+// SetBytes packs a band given MSB-first, as returned by the hash, into dst.
+// Only the first w bits of b are used.
+func SetBytes(dst []uint64, b []byte, w int) {
+	for t := range dst {
+		dst[t] = 0
+	}
+	for j := 0; j < w/8; j++ {
+		dst[j/8] |= uint64(bits.Reverse8(b[j])) << (8 * (j % 8))
+	}
+}
+
+// Eliminate brings the rows to echelon form in place. On success it returns
+// the pivot column of every sorted row; otherwise it returns the sorted
+// index of the first row without a pivot.
+func (s *Solver) Eliminate() ([]int, int) {
+	piv := make([]int, s.N)
+	for i := 0; i < s.N; i++ {
+		ri := s.Row(i)
+		j := lowest(ri)
+		if j < 0 {
+			return nil, i
+		}
+		c := s.Pos[i] + j
+		piv[i] = c
+		vi := s.Val(i)
+		for k := i + 1; k < s.N && s.Pos[k] <= c; k++ {
+			off := c - s.Pos[k]
+			rk := s.Row(k)
+			if rk[off>>6]>>(off&63)&1 == 0 {
+				continue
+			}
+			xorShifted(rk, ri, s.Pos[k]-s.Pos[i], off>>6)
+			if s.Record {
+				s.Ops = append(s.Ops, k, i)
+			}
+			vk := s.Val(k)
+			for t := range vk {
+				vk[t] ^= vi[t]
+			}
+		}
+	}
+	return piv, -1
+}
+
+// Replay applies the row operations recorded by Eliminate to Vals, which
+// brings freshly loaded values to the state Eliminate would have left them
+// in without touching the rows again.
+func (s *Solver) Replay() {
+	for t := 0; t+1 < len(s.Ops); t += 2 {
+		vk, vi := s.Val(s.Ops[t]), s.Val(s.Ops[t+1])
+		for u := range vk {
+			vk[u] ^= vi[u]
+		}
+	}
+}
+
+// BackSubstitute solves for the pivot slots of p, which holds M values of VW
+// words each. Non-pivot slots are read as they are, so callers may fill them
+// with random values first.
+func (s *Solver) BackSubstitute(piv []int, p []uint64) {
+	vw := s.VW
+	for i := s.N - 1; i >= 0; i-- {
+		dst := p[piv[i]*vw : (piv[i]+1)*vw]
+		copy(dst, s.Val(i))
+		pos := s.Pos[i]
+		for t, x := range s.Row(i) {
+			for x != 0 {
+				col := pos + t*64 + bits.TrailingZeros64(x)
+				x &= x - 1
+				if col == piv[i] {
+					continue
+				}
+				src := p[col*vw : (col+1)*vw]
+				for u := range dst {
+					dst[u] ^= src[u]
+				}
+			}
+		}
+	}
+}
+
+// Check returns the first row with a bit set at or above W, or whose pivot
+// in piv is not Pos plus its lowest set bit, and -1 when every row is in the
+// echelon form Eliminate leaves. BackSubstitute relies on both.
+func (s *Solver) Check(piv []int) int {
+	for i := 0; i < s.N; i++ {
+		row := s.Row(i)
+		if s.W%64 != 0 && row[s.RW-1]>>(s.W%64) != 0 {
+			return i
+		}
+		if j := lowest(row); j < 0 || s.Pos[i]+j != piv[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+// lowest 返回最低的非零位，全零时返回 -1
+func lowest(row []uint64) int {
+	for t, x := range row {
+		if x != 0 {
+			return t*64 + bits.TrailingZeros64(x)
+		}
+	}
+	return -1
+}
+
+// xorShifted sets dst[j] ^= src[j+d] for every bit j of dst, starting at
+// word from; dst and src hold the same number of words.
+func xorShifted(dst, src []uint64, d, from int) {
+	wo, bo := d>>6, uint(d&63)
+	n := len(src) - wo
+	if bo == 0 {
+		for t := from; t < n; t++ {
+			dst[t] ^= src[t+wo]
+		}
+		return
+	}
+	for t := from; t < n-1; t++ {
+		dst[t] ^= src[t+wo]>>bo | src[t+wo+1]<<(64-bo)
+	}
+	if from <= n-1 && n > 0 {
+		dst[n-1] ^= src[n-1+wo] >> bo
+	}
+}
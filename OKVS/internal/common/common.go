@@ -0,0 +1,184 @@
+// Package common holds the helpers shared by the OKVS backends: parameter
+// checks, error constructors, retry and parallel decoding.
+package common
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"golang.org/x/crypto/blake2b"
+)
+
+// ParamError 返回包装了 okvs.ErrParams 的错误
+func ParamError(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{okvs.ErrParams}, args...)...)
+}
+
+// FormatError 返回包装了 okvs.ErrFormat 的错误
+func FormatError(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{okvs.ErrFormat}, args...)...)
+}
+
+// CheckParams 检查各个变体共有的参数约束
+func CheckParams(n, m, w, r, p int, seed []byte) error {
+	switch {
+	case n <= 0:
+		return ParamError("N = %d must be positive", n)
+	case w <= 0 || w%8 != 0:
+		return ParamError("W = %d must be a positive multiple of 8", w)
+	case n > m:
+		return ParamError("N = %d exceeds M = %d", n, m)
+	case r <= 0:
+		return ParamError("R = %d must be positive", r)
+	case r+w > m:
+		return ParamError("R + W = %d exceeds M = %d", r+w, m)
+	case p != m:
+		return ParamError("len(P) = %d, want M = %d", p, m)
+	case len(seed) > blake2b.Size:
+		return okvs.ErrSeedSize
+	}
+	return nil
+}
+
+// SizeMismatch 返回包装了 okvs.ErrSizeMismatch 的错误
+func SizeMismatch(n, l int) error {
+	return fmt.Errorf("%w: N = %d, len(kvs) = %d", okvs.ErrSizeMismatch, n, l)
+}
+
+// ValueSize 返回包装了 okvs.ErrValueRange 的错误，说明第 i 个 value 的长度不对
+func ValueSize(i, got, want int) error {
+	return fmt.Errorf("%w: kvs[%d] has %d bytes, want %d", okvs.ErrValueRange, i, got, want)
+}
+
+// ValueMismatch 返回包装了 okvs.ErrValueMismatch 的错误
+func ValueMismatch(i int, key []byte) error {
+	return fmt.Errorf("%w: kvs[%d] (key %x)", okvs.ErrValueMismatch, i, key)
+}
+
+// EncodeWithRetry runs encode and, while it fails with ErrSingularSystem,
+// stores a fresh seed in *seed and tries again, at most retries more times.
+// Every backend's EncodeWithRetry passes its own Seed field, so the seed
+// that succeeded stays in the structure and Decode hashes with it.
+func EncodeWithRetry(retries int, seed *[]byte, encode func() error) error {
+	if len(*seed) > blake2b.Size {
+		return okvs.ErrSeedSize
+	}
+	err := encode()
+	for i := 0; i < retries && errors.Is(err, okvs.ErrSingularSystem); i++ {
+		s, serr := okvs.NewSeed()
+		if serr != nil {
+			return serr
+		}
+		*seed = s
+		err = encode()
+	}
+	return err
+}
+
+// NilValue 返回包装了 okvs.ErrParams 的错误，说明 pairs[i] 的 value 是 nil
+func NilValue(i int) error {
+	return ParamError("pairs[%d] has a nil value", i)
+}
+
+// ToUint32 把 value 转成 uint32，超出 32 位时返回 okvs.ErrValueRange，nil 时返回 okvs.ErrParams
+func ToUint32(v *big.Int) (uint32, error) {
+	if v == nil {
+		return 0, ParamError("nil value")
+	}
+	if v.Sign() < 0 || v.BitLen() > 32 {
+		return 0, okvs.ErrValueRange
+	}
+	return uint32(v.Uint64()), nil
+}
+
+// DecodeBatch 分块并行地对每个 key 调用 decode
+func DecodeBatch(keys [][]byte, decode func(key []byte) *big.Int) []*big.Int {
+	block := 2048
+	res := make([]*big.Int, len(keys))
+	var wg sync.WaitGroup
+	for i := 0; i < len(keys); i = i + block {
+		end := i + block
+		if end > len(keys) {
+			end = len(keys)
+		}
+		wg.Add(1)
+		go func(i, end int) {
+			defer wg.Done()
+			for j := i; j < end; j++ {
+				res[j] = decode(keys[j])
+			}
+		}(i, end)
+	}
+	wg.Wait()
+	return res
+}
+
+// ParallelFor 把 [0, n) 切成 GOMAXPROCS 块，并行地对每块调用 f
+func ParallelFor(n int, f func(lo, hi int)) {
+	parallel(n, runtime.GOMAXPROCS(0), 1024, f)
+}
+
+// ParallelWorkers 和 ParallelFor 一样，但最多使用 workers 个 goroutine，
+// workers <= 0 时等同于 ParallelFor
+func ParallelWorkers(n, workers int, f func(lo, hi int)) {
+	if workers <= 0 {
+		ParallelFor(n, f)
+		return
+	}
+	parallel(n, workers, 1, f)
+}
+
+func parallel(n, workers, minBlock int, f func(lo, hi int)) {
+	if workers == 1 || n <= minBlock {
+		if n > 0 {
+			f(0, n)
+		}
+		return
+	}
+	block := (n + workers - 1) / workers
+	if block < minBlock {
+		block = minBlock
+	}
+	var wg sync.WaitGroup
+	for lo := 0; lo < n; lo += block {
+		hi := lo + block
+		if hi > n {
+			hi = n
+		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			f(lo, hi)
+		}(lo, hi)
+	}
+	wg.Wait()
+}
+
+// DecodeInto 用最多 workers 个 goroutine 把 keys[i] 的解码结果写到 out[i]，
+// out 比 keys 短时返回包装了 okvs.ErrSizeMismatch 的错误
+func DecodeInto(keys [][]byte, out []uint32, workers int, decode func(key []byte) uint32) error {
+	if len(out) < len(keys) {
+		return fmt.Errorf("%w: len(out) = %d, len(keys) = %d", okvs.ErrSizeMismatch, len(out), len(keys))
+	}
+	ParallelWorkers(len(keys), workers, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			out[i] = decode(keys[i])
+		}
+	})
+	return nil
+}
+
+// Mismatches 比较解码结果 got 和 want，按下标顺序返回不一致的位置
+func Mismatches(keys [][]byte, want, got []uint32) []okvs.Mismatch {
+	var res []okvs.Mismatch
+	for i := range want {
+		if got[i] != want[i] {
+			res = append(res, okvs.Mismatch{Index: i, Key: keys[i], Want: want[i], Got: got[i]})
+		}
+	}
+	return res
+}
@@ -0,0 +1,103 @@
+package common
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+// legacyHeader 是旧格式开头的 6 个 int32：N、M、W、B、R 和 len(P)
+const legacyHeader = 24
+
+// P 之后可选的标志字节的各个位。没有这个字节的旧文件都是 Hash32
+const (
+	LegacyBitPos = 1 << iota // 起始位置精确到 bit
+	LegacyHash64             // 起始位置用 Hash64 计算
+)
+
+// LegacyFlags 返回要写在 P 之后的标志字节，为 0 时不写
+func LegacyFlags(bitPos bool, h okvs.HashMode) byte {
+	var f byte
+	if bitPos {
+		f |= LegacyBitPos
+	}
+	if h == okvs.Hash64 {
+		f |= LegacyHash64
+	}
+	return f
+}
+
+// LegacyHash 返回标志字节 f 表示的 hash 方式
+func LegacyHash(f byte) okvs.HashMode {
+	if f&LegacyHash64 != 0 {
+		return okvs.Hash64
+	}
+	return okvs.Hash32
+}
+
+// CheckLegacy 检查参数能否放进旧格式的 int32 字段，放不下时应该用 WriteTo
+func CheckLegacy(n, m, w, r int) error {
+	for _, v := range []int{n, m, w, r} {
+		if v > math.MaxInt32 {
+			return FormatError("%d does not fit the 32-bit legacy format, use WriteTo", v)
+		}
+	}
+	return nil
+}
+
+// LegacyFile is the content of a headerless file written by the deprecated
+// SerializeOKVSBK and SerializeOKVSECC.
+type LegacyFile struct {
+	N, M, W, B, R int
+	P             []uint32
+	Tail          []byte // P 之后的可选字节
+}
+
+// ReadLegacy reads a headerless file into memory and parses it with
+// ParseLegacy.
+func ReadLegacy(filename string, extra int) (*LegacyFile, error) {
+	st, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !st.Mode().IsRegular() {
+		return nil, FormatError("%s is not a regular file", filename)
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLegacy(b, extra)
+}
+
+// ParseLegacy parses a headerless file held in b. It checks len(P) against
+// M and the size of b, and accepts at most extra bytes after P. P and Tail
+// refer to b where Uint32View allows it. The remaining cross-field checks
+// are left to the variant's Validate.
+func ParseLegacy(b []byte, extra int) (*LegacyFile, error) {
+	size := int64(len(b))
+	if size < legacyHeader {
+		return nil, FormatError("file has %d bytes, shorter than the %d-byte header", size, legacyHeader)
+	}
+	var head [6]int32
+	for i := range head {
+		head[i] = int32(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	f := &LegacyFile{N: int(head[0]), M: int(head[1]), W: int(head[2]), B: int(head[3]), R: int(head[4])}
+	plen := int64(head[5])
+	if plen != int64(f.M) {
+		return nil, FormatError("len(P) = %d, want M = %d", plen, f.M)
+	}
+	if plen <= 0 {
+		return nil, FormatError("M = %d must be positive", f.M)
+	}
+	want := legacyHeader + 4*plen
+	if size < want || size > want+int64(extra) {
+		return nil, FormatError("file has %d bytes, want %d for M = %d", size, want, f.M)
+	}
+	f.P = Uint32View(b[legacyHeader:want])
+	f.Tail = b[want:]
+	return f, nil
+}
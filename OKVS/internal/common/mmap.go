@@ -0,0 +1,157 @@
+package common
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"unsafe"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+// Mapping is the content of a file, memory-mapped read-only where the
+// platform allows it and read into memory otherwise. Data must not be
+// written to, and must not be used after Close.
+type Mapping struct {
+	Data   []byte
+	mapped bool
+}
+
+// Map opens filename and maps it. When mmap is unavailable or fails, for
+// example on an empty file or a file system without mmap, it reads the
+// file instead, so callers only see the difference through Mapped.
+func Map(filename string) (*Mapping, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	st, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if size := st.Size(); size > 0 && st.Mode().IsRegular() && int64(int(size)) == size {
+		if b, err := mmap(file, int(size)); err == nil {
+			return &Mapping{Data: b, mapped: true}, nil
+		}
+	}
+	return readMapping(file)
+}
+
+// ReadFile reads filename into memory the way Map does when mmap is
+// unavailable.
+func ReadFile(filename string) (*Mapping, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readMapping(file)
+}
+
+func readMapping(file *os.File) (*Mapping, error) {
+	b, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return &Mapping{Data: b}, nil
+}
+
+// Mapped 返回 Data 是否是映射的文件，而不是读进内存的副本
+func (m *Mapping) Mapped() bool {
+	return m.mapped
+}
+
+// Shares 返回 p 是否直接指向 Data，而不是一份解码后的副本
+func (m *Mapping) Shares(p []uint32) bool {
+	if len(p) == 0 || len(m.Data) == 0 {
+		return false
+	}
+	start := uintptr(unsafe.Pointer(unsafe.SliceData(m.Data)))
+	at := uintptr(unsafe.Pointer(unsafe.SliceData(p)))
+	return at >= start && at < start+uintptr(len(m.Data))
+}
+
+// Close 解除映射，重复调用是安全的
+func (m *Mapping) Close() error {
+	b := m.Data
+	m.Data = nil
+	if !m.mapped || b == nil {
+		return nil
+	}
+	m.mapped = false
+	return munmap(b)
+}
+
+var littleEndian = binary.NativeEndian.Uint16([]byte{1, 0}) == 1
+
+// Uint32View 在 b 按 4 字节对齐且本机是小端时直接把 b 当作 []uint32，不复制；
+// 否则和 Uint32s 一样解码出一份副本
+func Uint32View(b []byte) []uint32 {
+	if len(b) < 4 || !littleEndian || uintptr(unsafe.Pointer(unsafe.SliceData(b)))%4 != 0 {
+		return Uint32s(b)
+	}
+	return unsafe.Slice((*uint32)(unsafe.Pointer(unsafe.SliceData(b))), len(b)/4)
+}
+
+// IsContainer 返回 b 是否以容器的 magic 开头，否则按旧的无头格式处理
+func IsContainer(b []byte) bool {
+	return len(b) >= len(okvs.Magic) && string(b[:len(okvs.Magic)]) == okvs.Magic
+}
+
+// MappedTable is a read-only table parsed from a Mapping, the shared part
+// of ourf2.MappedOKVSBK and ecdlp.MappedOKVSECC. T's P may point into the
+// mapping, so T must not be used after Close.
+type MappedTable[T any] struct {
+	T      T
+	m      *Mapping
+	shared bool
+}
+
+// NewMappedTable parses m with fromContainer when it holds a container and
+// with fromLegacy when it holds a headerless file; p returns the P of the
+// parsed table. On error it closes m.
+func NewMappedTable[T any](m *Mapping, fromContainer func(*okvs.Header, []byte) (T, error), fromLegacy func(*LegacyFile) (T, error), p func(T) []uint32) (*MappedTable[T], error) {
+	t, err := parseMapped(m.Data, fromContainer, fromLegacy)
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	return &MappedTable[T]{T: t, m: m, shared: m.Mapped() && m.Shares(p(t))}, nil
+}
+
+func parseMapped[T any](b []byte, fromContainer func(*okvs.Header, []byte) (T, error), fromLegacy func(*LegacyFile) (T, error)) (T, error) {
+	if IsContainer(b) {
+		h, payload, err := okvs.ParseContainer(b)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		return fromContainer(h, payload)
+	}
+	f, err := ParseLegacy(b, 1)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return fromLegacy(f)
+}
+
+// Mapped 返回 T 是否直接读映射的文件，而不是读进内存或解码出的副本
+func (t *MappedTable[T]) Mapped() bool {
+	return t.shared
+}
+
+// CheckDigest 检查容器末尾的摘要，会读一遍整个文件；旧格式没有摘要，总是返回 nil
+func (t *MappedTable[T]) CheckDigest() error {
+	if !IsContainer(t.m.Data) {
+		return nil
+	}
+	return okvs.CheckDigest(t.m.Data)
+}
+
+// Close 解除映射，重复调用是安全的
+func (t *MappedTable[T]) Close() error {
+	t.shared = false
+	return t.m.Close()
+}
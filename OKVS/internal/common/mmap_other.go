@@ -0,0 +1,17 @@
+//go:build !unix
+
+package common
+
+import (
+	"errors"
+	"os"
+)
+
+// 没有 mmap 的平台上 Map 总是把文件读进内存
+func mmap(file *os.File, size int) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func munmap(b []byte) error {
+	return nil
+}
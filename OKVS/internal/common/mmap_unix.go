@@ -0,0 +1,17 @@
+//go:build unix
+
+package common
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func mmap(file *os.File, size int) ([]byte, error) {
+	return unix.Mmap(int(file.Fd()), 0, size, unix.PROT_READ, unix.MAP_SHARED)
+}
+
+func munmap(b []byte) error {
+	return unix.Munmap(b)
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+// PutUint32s 把 p 写成小端的 4 字节一组
+func PutUint32s(p []uint32) []byte {
+	buf := make([]byte, 4*len(p))
+	for j, v := range p {
+		binary.LittleEndian.PutUint32(buf[4*j:], v)
+	}
+	return buf
+}
+
+// Uint32s 是 PutUint32s 的逆
+func Uint32s(b []byte) []uint32 {
+	p := make([]uint32, len(b)/4)
+	for j := range p {
+		p[j] = binary.LittleEndian.Uint32(b[4*j:])
+	}
+	return p
+}
+
+// WriteUint32s 把 P 为 M 个 uint32 的变体写成每个槽位 4 字节的容器，h.ValueWidth 被设为 4
+func WriteUint32s(w io.Writer, h *okvs.Header, p []uint32) (int64, error) {
+	h.ValueWidth = 4
+	return okvs.WriteContainer(w, h, PutUint32s(p))
+}
+
+// Uint32Payload 检查容器保存的是每个槽位 4 字节的变体 v，返回用 Uint32View 得到的 P
+func Uint32Payload(h *okvs.Header, v okvs.Variant, payload []byte) ([]uint32, error) {
+	if err := h.Expect(v, 4, payload); err != nil {
+		return nil, err
+	}
+	return Uint32View(payload), nil
+}
+
+// BigWidth 返回能放下 p 中所有非负整数的最小字节数，至少为 1，nil 按 0 处理
+func BigWidth(p []*big.Int) int {
+	width := 1
+	for _, v := range p {
+		if v != nil {
+			width = max(width, (v.BitLen()+7)/8)
+		}
+	}
+	return width
+}
+
+// PutBigs 把 p 中的每个整数写成 width 字节的大端，nil 写成 0
+func PutBigs(p []*big.Int, width int) []byte {
+	buf := make([]byte, width*len(p))
+	for j, v := range p {
+		if v != nil {
+			v.FillBytes(buf[width*j : width*(j+1)])
+		}
+	}
+	return buf
+}
+
+// Bigs 是 PutBigs 的逆，返回 n 个新分配的整数
+func Bigs(b []byte, n, width int) []*big.Int {
+	p := make([]*big.Int, n)
+	for j := range p {
+		p[j] = new(big.Int).SetBytes(b[width*j : width*(j+1)])
+	}
+	return p
+}
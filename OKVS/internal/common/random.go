@@ -0,0 +1,73 @@
+package common
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// PivotMask 标记哪些位置是某一行的主元，piv 中的 -1 会被跳过
+func PivotMask(m int, piv []int) []bool {
+	mask := make([]bool, m)
+	for _, p := range piv {
+		if p >= 0 {
+			mask[p] = true
+		}
+	}
+	return mask
+}
+
+// RandomBytes 从 rnd 中读取 n 个字节
+func RandomBytes(rnd io.Reader, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rnd, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// FillUint32 fills every non-pivot slot of p from rnd and clears the pivot
+// slots, so that back-substitution only has to overwrite the pivots and the
+// finished OKVS looks uniformly random.
+func FillUint32(rnd io.Reader, p []uint32, piv []int) error {
+	mask := PivotMask(len(p), piv)
+	buf, err := RandomBytes(rnd, 4*len(p))
+	if err != nil {
+		return err
+	}
+	for j := range p {
+		if mask[j] {
+			p[j] = 0
+		} else {
+			p[j] = binary.LittleEndian.Uint32(buf[4*j:])
+		}
+	}
+	return nil
+}
+
+// FillWords is FillUint32 for P packed as vw words per slot: every non-pivot
+// slot gets a random value of the given bit width and the pivot slots are
+// cleared.
+func FillWords(rnd io.Reader, p []uint64, vw, width int, piv []int) error {
+	m := len(p) / vw
+	mask := PivotMask(m, piv)
+	buf, err := RandomBytes(rnd, 8*len(p))
+	if err != nil {
+		return err
+	}
+	for j := 0; j < m; j++ {
+		for t := 0; t < vw; t++ {
+			var x uint64
+			if !mask[j] {
+				x = binary.LittleEndian.Uint64(buf[8*(j*vw+t):])
+				switch rest := width - 64*t; {
+				case rest <= 0:
+					x = 0
+				case rest < 64:
+					x &= 1<<uint(rest) - 1
+				}
+			}
+			p[j*vw+t] = x
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Package kernel holds the bit-level helpers shared by the GF(2) OKVS
+// backends: the byte-shift XOR kernels and MSB-first bit access.
+package kernel
+
+var bitMasks = [8]byte{
+	0x80, // 1000 0000
+	0x40, // 0100 0000
+	0x20, // 0010 0000
+	0x10, // 0001 0000
+	0x08, // 0000 1000
+	0x04, // 0000 0100
+	0x02, // 0000 0010
+	0x01, // 0000 0001
+}
+
+// GetBit 返回 b 的第 n 位，第 0 位是最高位
+func GetBit(b byte, n int) bool {
+	return b&bitMasks[n] > 0
+}
+
+// XorShiftBits sets bit j of dst ^= bit j+shift of src for every bit of dst,
+// with bits numbered MSB-first as in GetBit. It is XorShift for shifts that
+// are not a multiple of 8; bits past the end of src read as zero.
+func XorShiftBits(dst, src []byte, shift int) {
+	q, r := shift/8, uint(shift%8)
+	for t := 0; t+q < len(src) && t < len(dst); t++ {
+		b := src[t+q] << r
+		if r != 0 && t+q+1 < len(src) {
+			b |= src[t+q+1] >> (8 - r)
+		}
+		dst[t] ^= b
+	}
+}
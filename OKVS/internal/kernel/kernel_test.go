@@ -0,0 +1,61 @@
+package kernel
+
+import (
+	"bytes"
+	mrand "math/rand"
+	"testing"
+)
+
+// TestXorShift 比较 XorShift 和逐字节的定义，也覆盖结果写回 arr2 的用法
+func TestXorShift(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(1))
+	for it := 0; it < 200; it++ {
+		shifts := rng.Intn(300)
+		if it < 70 {
+			shifts = it
+		}
+		for shiftnum := 0; shiftnum < 8; shiftnum++ {
+			arr1 := make([]byte, shifts+shiftnum)
+			arr2 := make([]byte, shifts)
+			rng.Read(arr1)
+			rng.Read(arr2)
+			want := make([]byte, shifts)
+			for i := range want {
+				want[i] = arr1[i+shiftnum] ^ arr2[i]
+			}
+
+			got := make([]byte, shifts)
+			XorShift(got, arr1, arr2, shifts, shiftnum)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("XorShift with %d bytes, shiftnum %d: got %x, want %x", shifts, shiftnum, got, want)
+			}
+			// 结果写回 arr2 是 ecdlp ShiftRowBK 的用法
+			XorShift(arr2, arr1, arr2, shifts, shiftnum)
+			if !bytes.Equal(arr2, want) {
+				t.Fatalf("XorShift in place with %d bytes, shiftnum %d: got %x, want %x", shifts, shiftnum, arr2, want)
+			}
+		}
+	}
+}
+
+// TestXorShiftBits 用 GetBit 逐位比较，src 末尾之后的位按 0 处理
+func TestXorShiftBits(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(2))
+	for it := 0; it < 500; it++ {
+		dst := make([]byte, 1+rng.Intn(40))
+		src := make([]byte, 1+rng.Intn(40))
+		rng.Read(dst)
+		rng.Read(src)
+		shift := rng.Intn(8 * len(src))
+		want := append([]byte(nil), dst...)
+		for j := 0; j < 8*len(dst); j++ {
+			if k := j + shift; k < 8*len(src) && GetBit(src[k/8], k%8) {
+				want[j/8] ^= 0x80 >> (j % 8)
+			}
+		}
+		XorShiftBits(dst, src, shift)
+		if !bytes.Equal(dst, want) {
+			t.Fatalf("XorShiftBits with shift %d: got %x, want %x", shift, dst, want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package kernel
+
+import "encoding/binary"
+
+// XorShift sets result[i] = arr1[i+shiftnum] ^ arr2[i] for i < shifts. It
+// works on 64-bit words and finishes the tail byte by byte, and gives the
+// same bytes as the old AVX2 xor_shift_simd. result may alias arr2.
+func XorShift(result, arr1, arr2 []byte, shifts, shiftnum int) {
+	if shifts <= 0 {
+		return
+	}
+	src := arr1[shiftnum : shiftnum+shifts]
+	acc := arr2[:shifts]
+	dst := result[:shifts]
+	i := 0
+	for ; i+8 <= shifts; i += 8 {
+		binary.LittleEndian.PutUint64(dst[i:], binary.LittleEndian.Uint64(src[i:])^binary.LittleEndian.Uint64(acc[i:]))
+	}
+	for ; i < shifts; i++ {
+		dst[i] = src[i] ^ acc[i]
+	}
+}
@@ -0,0 +1,157 @@
+// Package linear exposes the decode map of an OKVS as a sparse band matrix.
+// Row i of the N×M matrix belongs to the i-th key and is nonzero only in
+// columns [Pos[i], Pos[i]+W), so Decode(key_i) = (A·P)_i. The transpose map
+// y ↦ Aᵀ·y is what VOLE-based PSI and OPRF need on the other side.
+package linear
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Word 是 GF(2) 矩阵可以作用的元素类型，加法就是异或
+type Word interface {
+	~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// F2 is a band matrix over GF(2). Row i has a one in column Pos[i]+j for
+// every set bit j of Rows[i], with bits numbered MSB-first within each byte,
+// exactly as the backends' hash2 returns them.
+type F2 struct {
+	N, M, W int
+	Pos     []int
+	Rows    [][]byte
+}
+
+// each 依次对第 i 行的每个非零列调用 f
+func (a *F2) each(i int, f func(col int)) {
+	pos := a.Pos[i]
+	for t, b := range a.Rows[i][:a.W/8] {
+		for b != 0 {
+			j := bits.LeadingZeros8(b)
+			b &^= 0x80 >> j
+			f(pos + 8*t + j)
+		}
+	}
+}
+
+// Mul returns A·x for x of length M: entry i is the XOR of x over the
+// columns of row i, i.e. what Decode returns for the i-th key when x is P.
+func Mul[T Word](a *F2, x []T) []T {
+	y := make([]T, a.N)
+	for i := range y {
+		var acc T
+		a.each(i, func(col int) { acc ^= x[col] })
+		y[i] = acc
+	}
+	return y
+}
+
+// MulTranspose returns Aᵀ·y for y of length N: entry j is the XOR of y over
+// the rows that have a one in column j.
+func MulTranspose[T Word](a *F2, y []T) []T {
+	x := make([]T, a.M)
+	for i := range y {
+		yi := y[i]
+		a.each(i, func(col int) { x[col] ^= yi })
+	}
+	return x
+}
+
+// CSR 返回压缩行格式，第 i 行的列是 indices[indptr[i]:indptr[i+1]]，值都是 1
+func (a *F2) CSR() (indptr, indices []int) {
+	indptr = make([]int, a.N+1)
+	for i := 0; i < a.N; i++ {
+		a.each(i, func(col int) { indices = append(indices, col) })
+		indptr[i+1] = len(indices)
+	}
+	return indptr, indices
+}
+
+// COO 返回坐标格式，每个 1 对应一组 (rows[t], cols[t])
+func (a *F2) COO() (rows, cols []int) {
+	for i := 0; i < a.N; i++ {
+		a.each(i, func(col int) {
+			rows = append(rows, i)
+			cols = append(cols, col)
+		})
+	}
+	return rows, cols
+}
+
+// Fp is a band matrix over the prime field Z_Q. Coef[i][j] is the entry in
+// column Pos[i]+j; nil and zero entries are both treated as zero.
+type Fp struct {
+	N, M, W int
+	Q       *big.Int
+	Pos     []int
+	Coef    [][]*big.Int
+}
+
+// Mul returns A·x mod Q for x of length M.
+func (a *Fp) Mul(x []*big.Int) []*big.Int {
+	y := make([]*big.Int, a.N)
+	t := new(big.Int)
+	for i := range y {
+		acc := new(big.Int)
+		for j, c := range a.Coef[i] {
+			if c == nil || c.Sign() == 0 {
+				continue
+			}
+			acc.Add(acc, t.Mul(c, x[a.Pos[i]+j]))
+		}
+		y[i] = acc.Mod(acc, a.Q)
+	}
+	return y
+}
+
+// MulTranspose returns Aᵀ·y mod Q for y of length N.
+func (a *Fp) MulTranspose(y []*big.Int) []*big.Int {
+	x := make([]*big.Int, a.M)
+	for j := range x {
+		x[j] = new(big.Int)
+	}
+	t := new(big.Int)
+	for i := range y {
+		for j, c := range a.Coef[i] {
+			if c == nil || c.Sign() == 0 {
+				continue
+			}
+			col := a.Pos[i] + j
+			x[col].Add(x[col], t.Mul(c, y[i]))
+		}
+	}
+	for j := range x {
+		x[j].Mod(x[j], a.Q)
+	}
+	return x
+}
+
+// CSR 返回压缩行格式，只包含非零项
+func (a *Fp) CSR() (indptr, indices []int, values []*big.Int) {
+	indptr = make([]int, a.N+1)
+	for i := 0; i < a.N; i++ {
+		for j, c := range a.Coef[i] {
+			if c != nil && c.Sign() != 0 {
+				indices = append(indices, a.Pos[i]+j)
+				values = append(values, c)
+			}
+		}
+		indptr[i+1] = len(indices)
+	}
+	return indptr, indices, values
+}
+
+// COO 返回坐标格式，只包含非零项
+func (a *Fp) COO() (rows, cols []int, values []*big.Int) {
+	for i := 0; i < a.N; i++ {
+		for j, c := range a.Coef[i] {
+			if c != nil && c.Sign() != 0 {
+				rows = append(rows, i)
+				cols = append(cols, a.Pos[i]+j)
+				values = append(values, c)
+			}
+		}
+	}
+	return rows, cols, values
+}
@@ -0,0 +1,179 @@
+package linear
+
+import (
+	"math/big"
+	mrand "math/rand"
+	"testing"
+)
+
+// f2Fixture 是一个 3×12、W = 8 的小矩阵，dense 是手写的展开形式
+var (
+	f2Fixture = &F2{
+		N: 3, M: 12, W: 8,
+		Pos:  []int{0, 2, 4},
+		Rows: [][]byte{{0b10010001}, {0b01100000}, {0b10000011}},
+	}
+	f2Dense = [][]int{
+		{1, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 0},
+		{0, 0, 0, 1, 1, 0, 0, 0, 0, 0, 0, 0},
+		{0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1, 1},
+	}
+)
+
+func TestF2Sparse(t *testing.T) {
+	a := f2Fixture
+	indptr, indices := a.CSR()
+	rows, cols := a.COO()
+	var k int
+	for i := range f2Dense {
+		var csr []int
+		for j, v := range f2Dense[i] {
+			if v == 1 {
+				csr = append(csr, j)
+				if k >= len(rows) || rows[k] != i || cols[k] != j {
+					t.Fatalf("COO entry %d: got (%v, %v), want (%d, %d)", k, rows, cols, i, j)
+				}
+				k++
+			}
+		}
+		got := indices[indptr[i]:indptr[i+1]]
+		if len(got) != len(csr) {
+			t.Fatalf("CSR row %d: got %v, want %v", i, got, csr)
+		}
+		for k := range got {
+			if got[k] != csr[k] {
+				t.Fatalf("CSR row %d: got %v, want %v", i, got, csr)
+			}
+		}
+	}
+	if k != len(rows) {
+		t.Fatalf("COO: got %d entries, want %d", len(rows), k)
+	}
+}
+
+func TestF2MulTranspose(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(1))
+	a := f2Fixture
+	x := make([]uint32, a.M)
+	y := make([]uint32, a.N)
+	for j := range x {
+		x[j] = rng.Uint32()
+	}
+	for i := range y {
+		y[i] = rng.Uint32()
+	}
+	ax := Mul(a, x)
+	aty := MulTranspose(a, y)
+	for i := range f2Dense {
+		var want uint32
+		for j, v := range f2Dense[i] {
+			if v == 1 {
+				want ^= x[j]
+			}
+		}
+		if ax[i] != want {
+			t.Fatalf("Mul[%d]: got %d, want %d", i, ax[i], want)
+		}
+	}
+	for j := 0; j < a.M; j++ {
+		var want uint32
+		for i := range f2Dense {
+			if f2Dense[i][j] == 1 {
+				want ^= y[i]
+			}
+		}
+		if aty[j] != want {
+			t.Fatalf("MulTranspose[%d]: got %d, want %d", j, aty[j], want)
+		}
+	}
+	// 每一位上都有 <A·x, y> = <x, Aᵀ·y>
+	var l, r uint32
+	for i := range y {
+		l ^= ax[i] & y[i]
+	}
+	for j := range x {
+		r ^= x[j] & aty[j]
+	}
+	if l != r {
+		t.Fatalf("<Ax, y> = %#x, <x, Aᵀy> = %#x", l, r)
+	}
+}
+
+// fpFixture 是 Z_7 上的 3×6、W = 3 的小矩阵，包含 nil 和 0 系数
+var (
+	fpFixture = &Fp{
+		N: 3, M: 6, W: 3,
+		Q:   big.NewInt(7),
+		Pos: []int{0, 1, 3},
+		Coef: [][]*big.Int{
+			{big.NewInt(1), nil, big.NewInt(3)},
+			{big.NewInt(0), big.NewInt(6), big.NewInt(2)},
+			{big.NewInt(5), big.NewInt(1), nil},
+		},
+	}
+	fpDense = [][]int64{
+		{1, 0, 3, 0, 0, 0},
+		{0, 0, 6, 2, 0, 0},
+		{0, 0, 0, 5, 1, 0},
+	}
+)
+
+func TestFpSparse(t *testing.T) {
+	a := fpFixture
+	indptr, indices, values := a.CSR()
+	rows, cols, cvals := a.COO()
+	var k int
+	for i := range fpDense {
+		if indptr[i] != k {
+			t.Fatalf("CSR indptr[%d]: got %d, want %d", i, indptr[i], k)
+		}
+		for j, v := range fpDense[i] {
+			if v == 0 {
+				continue
+			}
+			if k >= len(indices) || indices[k] != j || values[k].Int64() != v {
+				t.Fatalf("CSR entry %d: want column %d value %d", k, j, v)
+			}
+			if rows[k] != i || cols[k] != j || cvals[k].Int64() != v {
+				t.Fatalf("COO entry %d: got (%d, %d, %v), want (%d, %d, %d)", k, rows[k], cols[k], cvals[k], i, j, v)
+			}
+			k++
+		}
+	}
+	if len(indices) != k || len(rows) != k {
+		t.Fatalf("got %d CSR and %d COO entries, want %d", len(indices), len(rows), k)
+	}
+}
+
+func TestFpMulTranspose(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(2))
+	a := fpFixture
+	x := make([]*big.Int, a.M)
+	y := make([]*big.Int, a.N)
+	for j := range x {
+		x[j] = new(big.Int).Rand(rng, a.Q)
+	}
+	for i := range y {
+		y[i] = new(big.Int).Rand(rng, a.Q)
+	}
+	ax := a.Mul(x)
+	aty := a.MulTranspose(y)
+	for i := range fpDense {
+		want := new(big.Int)
+		for j, v := range fpDense[i] {
+			want.Add(want, new(big.Int).Mul(big.NewInt(v), x[j]))
+		}
+		if want.Mod(want, a.Q); ax[i].Cmp(want) != 0 {
+			t.Fatalf("Mul[%d]: got %v, want %v", i, ax[i], want)
+		}
+	}
+	for j := 0; j < a.M; j++ {
+		want := new(big.Int)
+		for i := range fpDense {
+			want.Add(want, new(big.Int).Mul(big.NewInt(fpDense[i][j]), y[i]))
+		}
+		if want.Mod(want, a.Q); aty[j].Cmp(want) != 0 {
+			t.Fatalf("MulTranspose[%d]: got %v, want %v", j, aty[j], want)
+		}
+	}
+}
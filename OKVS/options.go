@@ -0,0 +1,115 @@
+package okvs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+)
+
+const (
+	DefaultExpansion = 1.03 // 默认的扩张率 M/N
+	DefaultW         = 360  // 默认的随机块长度
+)
+
+// ErrParams 表示 OKVS 的参数彼此不一致
+var ErrParams = errors.New("okvs: invalid parameters")
+
+// Config 收集构造函数的可选参数
+type Config struct {
+	Expansion float64   // M = round(N * Expansion)
+	W         int       // 随机块的长度，必须是 8 的倍数
+	Seed      []byte    // hash 种子
+	Tag       []byte    // 域分离标签
+	Rand      io.Reader // 随机填充非主元位置
+	Modulus   *big.Int  // 素数域后端的模数 q
+	BitPos    bool      // OKVSBK 的起始位置精确到 bit，不再取整到 8 的倍数
+	ValueSize int       // value 的字节数，0 表示使用后端的默认值
+	RandCoef  bool      // 素数域后端的带内系数使用由 key 派生的随机域元素
+	Workers   int       // 批量解码的并发数，bpsy23 和 bigint 编码时也用它，0 表示 GOMAXPROCS
+	Hash      HashMode  // 起始位置的 hash 方式，默认 Hash64
+}
+
+// Option 修改 Config
+type Option func(*Config)
+
+// WithExpansion 设置扩张率
+func WithExpansion(e float64) Option {
+	return func(c *Config) { c.Expansion = e }
+}
+
+// WithBandWidth 设置随机块的长度 W
+func WithBandWidth(w int) Option {
+	return func(c *Config) { c.W = w }
+}
+
+// WithSeed 设置 hash 种子
+func WithSeed(seed []byte) Option {
+	return func(c *Config) { c.Seed = seed }
+}
+
+// WithTag 设置域分离标签
+func WithTag(tag []byte) Option {
+	return func(c *Config) { c.Tag = tag }
+}
+
+// WithRand 设置用于随机填充的 CSPRNG
+func WithRand(rnd io.Reader) Option {
+	return func(c *Config) { c.Rand = rnd }
+}
+
+// WithModulus 设置素数域后端的模数 q
+func WithModulus(q *big.Int) Option {
+	return func(c *Config) { c.Modulus = q }
+}
+
+// WithBitPositions 让字节分桶的后端使用精确到 bit 的起始位置
+func WithBitPositions(on bool) Option {
+	return func(c *Config) { c.BitPos = on }
+}
+
+// WithValueSize 设置定长 value 的字节数，bigint 用它作为随机填充的宽度
+func WithValueSize(l int) Option {
+	return func(c *Config) { c.ValueSize = l }
+}
+
+// WithRandomCoefficients 让素数域后端的带内系数取随机域元素而不是 0/1
+func WithRandomCoefficients(on bool) Option {
+	return func(c *Config) { c.RandCoef = on }
+}
+
+// WithWorkers 设置 DecodeBatch 使用的 goroutine 数，bpsy23 和 bigint 编码时计算 hash 也用它，
+// n <= 0 表示 GOMAXPROCS
+func WithWorkers(n int) Option {
+	return func(c *Config) { c.Workers = n }
+}
+
+// WithHashMode 设置起始位置的 hash 方式，Hash32 只用于和旧文件兼容
+func WithHashMode(m HashMode) Option {
+	return func(c *Config) { c.Hash = m }
+}
+
+// NewConfig 返回应用了 opts 的默认配置
+func NewConfig(opts ...Option) Config {
+	c := Config{Expansion: DefaultExpansion, W: DefaultW, Hash: Hash64}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// Size derives M from n and the expansion ratio.
+func (c *Config) Size(n int) (int, error) {
+	if n <= 0 {
+		return 0, paramError("N = %d must be positive", n)
+	}
+	if !(c.Expansion > 1) || math.IsInf(c.Expansion, 0) {
+		return 0, paramError("expansion %v must be greater than 1", c.Expansion)
+	}
+	return int(math.Round(float64(n) * c.Expansion)), nil
+}
+
+func paramError(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrParams}, args...)...)
+}
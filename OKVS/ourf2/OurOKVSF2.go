@@ -0,0 +1,321 @@
+package ourf2
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/OurOKVS/OKVS/internal/kernel"
+)
+
+// 定义System结构体
+type SystemBK struct {
+	Pos   int
+	BPos  int
+	Row   []byte
+	Value uint32
+	Key   []byte
+}
+
+type OKVSBK struct {
+	N    int //okvs存储的k-v长度
+	M    int //okvs的实际长度
+	W    int //随机块的长度
+	B    int //桶的个度
+	R    int // hashrange
+	P    []uint32
+	Seed []byte    // hash 种子，为空时等价于不加 key 的 blake2b
+	Tag  []byte    // 域分离标签
+	Rand io.Reader // 非空时用它随机填充非主元位置，使 OKVS 看起来是均匀随机的
+	// BitPos 为 true 时起始位置精确到 bit，和 BPSY23 一样；
+	// 否则取整到 8 的倍数，行之间只做字节移位
+	BitPos bool
+	// Workers 是 DecodeBatch 使用的 goroutine 数，0 表示 GOMAXPROCS
+	Workers int
+	// Hash 是起始位置的 hash 方式，零值是 Hash32，构造函数默认用 Hash64
+	Hash okvs.HashMode
+}
+
+// NewOKVSBK 根据 n、扩张率和 W 构造 OKVSBK，M = round(n*e)，R = M - W
+func NewOKVSBK(n int, opts ...okvs.Option) (*OKVSBK, error) {
+	c := okvs.NewConfig(opts...)
+	m, err := c.Size(n)
+	if err != nil {
+		return nil, err
+	}
+	r := &OKVSBK{
+		N:       n,
+		M:       m,
+		W:       c.W,
+		B:       c.W / 8,
+		R:       m - c.W,
+		P:       make([]uint32, m),
+		Seed:    c.Seed,
+		Tag:     c.Tag,
+		Rand:    c.Rand,
+		BitPos:  c.BitPos,
+		Workers: c.Workers,
+		Hash:    c.Hash,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate 检查参数是否一致
+func (r *OKVSBK) Validate() error {
+	if err := common.CheckParams(r.N, r.M, r.W, r.R, len(r.P), r.Seed); err != nil {
+		return err
+	}
+	if err := r.Hash.Check(r.R); err != nil {
+		return err
+	}
+	if r.B != r.W/8 {
+		return common.ParamError("B = %d, want W/8 = %d", r.B, r.W/8)
+	}
+	return nil
+}
+
+// SerializeOKVSBK 把 OKVSBK 以旧的无头格式写到文件，不保存 Seed 和 Tag。
+// 参数超过 int32 时返回错误。
+//
+// Deprecated: 使用 OKVSBK.WriteTo，它写入带版本、种子和摘要的容器。
+func SerializeOKVSBK(filename string, data OKVSBK) error {
+	if err := common.CheckLegacy(data.N, data.M, data.W, data.R); err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// 写入基本数据
+	err = binary.Write(file, binary.LittleEndian, int32(data.N))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, int32(data.M))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, int32(data.W))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, int32(data.B))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, int32(data.R))
+	if err != nil {
+		return err
+	}
+
+	// 写入 P 数组长度和内容
+	err = binary.Write(file, binary.LittleEndian, int32(len(data.P)))
+	if err != nil {
+		return err
+	}
+	err = binary.Write(file, binary.LittleEndian, data.P)
+	if err != nil {
+		return err
+	}
+
+	// 精确到 bit 的起始位置和 Hash64 用 P 之后的一个标志字节表示，旧文件没有这个字节
+	if f := common.LegacyFlags(data.BitPos, data.Hash); f != 0 {
+		_, err = file.Write([]byte{f})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeserializeOKVSBK 读取 SerializeOKVSBK 写的旧格式文件。
+//
+// Deprecated: 使用 ReadOKVSBK 读取 WriteTo 写的容器。
+func DeserializeOKVSBK(filename string) (OKVSBK, error) {
+	f, err := common.ReadLegacy(filename, 1)
+	if err != nil {
+		return OKVSBK{}, err
+	}
+	r, err := okvsbkFromLegacy(f)
+	if err != nil {
+		return OKVSBK{}, err
+	}
+	return *r, nil
+}
+
+func okvsbkFromLegacy(f *common.LegacyFile) (*OKVSBK, error) {
+	data := &OKVSBK{N: f.N, M: f.M, W: f.W, B: f.B, R: f.R, P: f.P, Hash: okvs.Hash32}
+	// 精确到 bit 的起始位置和 Hash64 用 P 之后的一个标志字节表示
+	if len(f.Tail) == 1 {
+		if f.Tail[0]&^(common.LegacyBitPos|common.LegacyHash64) != 0 {
+			return nil, common.FormatError("legacy flags are %#x", f.Tail[0])
+		}
+		data.BitPos = f.Tail[0]&common.LegacyBitPos != 0
+		data.Hash = common.LegacyHash(f.Tail[0])
+	}
+	if err := data.Validate(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+type KVBK struct {
+	Key   []byte //key
+	Value uint32 //value
+}
+
+func (r *OKVSBK) hash1(key []byte) int {
+	return r.Hash.Pos(r.Seed, r.Tag, key, r.R)
+}
+
+// pos 返回 key 的起始位置，字节对齐模式下取整到 8 的倍数
+func (r *OKVSBK) pos(key []byte) int {
+	pos := r.hash1(key)
+	if !r.BitPos {
+		pos = pos / 8 * 8
+	}
+	return pos
+}
+
+func (r *OKVSBK) hash2(key []byte) []byte {
+	bandsize := r.W / 8
+	hashBytes := okvs.HashWithSeed(bandsize, r.Seed, r.Tag, key)
+	return hashBytes
+}
+
+func (r *OKVSBK) SetLine(i int, system *SystemBK, kv *KVBK) {
+	system.Pos = r.pos(kv.Key)
+	system.BPos = int(system.Pos / 8)
+	system.Row = r.hash2(kv.Key)
+	system.Value = kv.Value
+	system.Key = kv.Key
+}
+
+func (r *OKVSBK) Init(kvs []KVBK) []SystemBK {
+	systems := make([]SystemBK, r.N)
+	for i := 0; i < r.N; i++ {
+		r.SetLine(i, &systems[i], &kvs[i])
+	}
+	return systems
+}
+
+// Encode 用 word 打包的带状消元求解 P。hash 是并行计算的。
+func (r *OKVSBK) Encode(kvs []KVBK) (*OKVSBK, error) {
+	if len(kvs) != r.N {
+		return nil, common.SizeMismatch(r.N, len(kvs))
+	}
+	p, err := r.solve(func(i int) []byte { return kvs[i].Key }, 1, func(i int, dst []uint64) {
+		dst[0] = uint64(kvs[i].Value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for j := range r.P {
+		r.P[j] = uint32(p[j])
+	}
+	return r, nil
+}
+
+// EncodeWithRetry 是换 Seed 重试的 Encode，见 common.EncodeWithRetry
+func (r *OKVSBK) EncodeWithRetry(kvs []KVBK, retries int) (*OKVSBK, error) {
+	err := common.EncodeWithRetry(retries, &r.Seed, func() error {
+		_, err := r.Encode(kvs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *OKVSBK) ShiftRowBK(wg *sync.WaitGroup, i int, iend int, pivi int, systems *[]SystemBK) {
+	defer wg.Done()
+	for k := i; k < iend; k++ {
+		if (*systems)[k].Pos <= pivi {
+			posk := pivi - (*systems)[k].Pos
+			if kernel.GetBit((*systems)[k].Row[int(posk/8)], posk%8) {
+				shift := (*systems)[k].Pos - (*systems)[i].Pos
+				kernel.XorShiftBits((*systems)[k].Row, (*systems)[i].Row, shift)
+				(*systems)[k].Value = (*systems)[k].Value ^ (*systems)[i].Value
+			}
+		}
+	}
+}
+
+// ParEncode 和 Encode 相同，Encode 已经并行计算 hash，消元本身是顺序的
+func (r *OKVSBK) ParEncode(kvs []KVBK) (*OKVSBK, error) {
+	return r.Encode(kvs)
+}
+
+func (r *OKVSBK) Decode(key []byte) uint32 {
+	pos := r.pos(key)
+	row := r.hash2(key)
+	var res uint32 = 0
+	for j := pos; j < r.W+pos; j++ {
+		if kernel.GetBit(row[(j-pos)/8], (j-pos)%8) {
+			res = res ^ r.P[j]
+		}
+	}
+	return res
+
+}
+
+func (r *OKVSBK) DecodewithCheck(key []byte) (uint32, bool) {
+	pos := r.pos(key)
+	row := r.hash2(key)
+	var res uint32 = 0
+	for j := pos; j < r.W+pos; j++ {
+		if kernel.GetBit(row[(j-pos)/8], (j-pos)%8) {
+			res = res ^ r.P[j]
+		}
+	}
+	ok := true
+	if res > uint32(r.N) {
+		ok = false
+	}
+	return res, ok
+
+}
+
+// DecodeBatch 用 r.Workers 个 goroutine 把 keys[i] 解码到 out[i]，out 比 keys 短时返回错误
+func (r *OKVSBK) DecodeBatch(keys [][]byte, out []uint32) error {
+	return common.DecodeInto(keys, out, r.Workers, r.Decode)
+}
+
+// Verify 按 kvs 的顺序返回解码结果和 value 不一致的所有位置，全部一致时返回 nil
+func (r *OKVSBK) Verify(kvs []KVBK) []okvs.Mismatch {
+	keys, want := splitKVs(kvs)
+	got := make([]uint32, len(kvs))
+	r.DecodeBatch(keys, got)
+	return common.Mismatches(keys, want, got)
+}
+
+// ParDecode 解码 kvs 中的所有 key，第一个不一致的 value 作为包装了 okvs.ErrValueMismatch 的错误返回。
+// 只需要解码时使用 DecodeBatch，需要所有不一致的位置时使用 Verify
+func (r *OKVSBK) ParDecode(kvs []KVBK) ([]uint32, error) {
+	keys, want := splitKVs(kvs)
+	res := make([]uint32, len(kvs))
+	r.DecodeBatch(keys, res)
+	if ms := common.Mismatches(keys, want, res); len(ms) > 0 {
+		return res, common.ValueMismatch(ms[0].Index, ms[0].Key)
+	}
+	return res, nil
+}
+
+func splitKVs(kvs []KVBK) ([][]byte, []uint32) {
+	keys := make([][]byte, len(kvs))
+	vals := make([]uint32, len(kvs))
+	for i, kv := range kvs {
+		keys[i], vals[i] = kv.Key, kv.Value
+	}
+	return keys, vals
+}
@@ -0,0 +1,140 @@
+package ourf2
+
+import (
+	mrand "math/rand"
+	"sort"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/kernel"
+)
+
+const testN = 1000
+
+var testSeed = []byte("ourf2 test seed")
+
+func testKeys(rng *mrand.Rand, n int) [][]byte {
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = make([]byte, 16)
+		rng.Read(keys[i])
+	}
+	return keys
+}
+
+// encodeBK 用随机的 value 和随机填充编码 keys，返回 OKVSBK 和 value
+func encodeBK(t *testing.T, rng *mrand.Rand, keys [][]byte, opts ...okvs.Option) (*OKVSBK, []uint32) {
+	t.Helper()
+	r, err := NewOKVSBK(len(keys), append([]okvs.Option{okvs.WithSeed(testSeed), okvs.WithRand(rng)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kvs := make([]KVBK, len(keys))
+	vals := make([]uint32, len(keys))
+	for i := range kvs {
+		vals[i] = rng.Uint32()
+		kvs[i] = KVBK{Key: keys[i], Value: vals[i]}
+	}
+	if _, err := r.Encode(kvs); err != nil {
+		t.Fatal(err)
+	}
+	return r, vals
+}
+
+// encodeBytes 是 word 打包之前的编码：SystemBK 的行是 []byte，逐位找主元，
+// 行之间用 kernel.XorShift 做字节移位的异或。只用于字节对齐的起始位置。
+// 排序是稳定的，和 band.New 的计数排序得到同样的行顺序
+func encodeBytes(r *OKVSBK, kvs []KVBK) ([]uint32, bool) {
+	systems := r.Init(kvs)
+	sort.SliceStable(systems, func(i, j int) bool {
+		return systems[i].Pos < systems[j].Pos
+	})
+	piv := make([]int, r.N)
+	for i := range piv {
+		piv[i] = -1
+		for j := 0; j < r.W; j++ {
+			if !kernel.GetBit(systems[i].Row[j/8], j%8) {
+				continue
+			}
+			piv[i] = j + systems[i].Pos
+			for k := i + 1; k < r.N && systems[k].Pos <= piv[i]; k++ {
+				posk := piv[i] - systems[k].Pos
+				if kernel.GetBit(systems[k].Row[posk/8], posk%8) {
+					shiftnum := systems[k].BPos - systems[i].BPos
+					kernel.XorShift(systems[k].Row, systems[i].Row, systems[k].Row, r.B-shiftnum, shiftnum)
+					systems[k].Value ^= systems[i].Value
+				}
+			}
+			break
+		}
+		if piv[i] == -1 {
+			return nil, false
+		}
+	}
+	p := make([]uint32, r.M)
+	for i := r.N - 1; i >= 0; i-- {
+		res := systems[i].Value
+		for j := 0; j < r.W; j++ {
+			if kernel.GetBit(systems[i].Row[j/8], j%8) && systems[i].Pos+j != piv[i] {
+				res ^= p[systems[i].Pos+j]
+			}
+		}
+		p[piv[i]] = res
+	}
+	return p, true
+}
+
+// 没有随机填充时两种消元选同样的主元，得到的 P 逐个位置相同
+func TestBandMatchesBytes(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(10))
+	keys := testKeys(rng, testN)
+	kvs := make([]KVBK, testN)
+	for i := range kvs {
+		kvs[i] = KVBK{Key: keys[i], Value: rng.Uint32()}
+	}
+	r, err := NewOKVSBK(testN, okvs.WithSeed(testSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, ok := encodeBytes(r, kvs)
+	if !ok {
+		t.Fatal("the byte-wise elimination failed")
+	}
+	if _, err := r.Encode(kvs); err != nil {
+		t.Fatal(err)
+	}
+	for j := range want {
+		if r.P[j] != want[j] {
+			t.Fatalf("P[%d] = %d, want %d", j, r.P[j], want[j])
+		}
+	}
+}
+
+// BenchmarkEncode 比较 main.go 中 n = 2^20 的编码，band 是当前的 Encode，bytes 是 encodeBytes
+func BenchmarkEncode(b *testing.B) {
+	const n = 1 << 20
+	rng := mrand.New(mrand.NewSource(11))
+	keys := testKeys(rng, n)
+	kvs := make([]KVBK, n)
+	for i := range kvs {
+		kvs[i] = KVBK{Key: keys[i], Value: rng.Uint32()}
+	}
+	r, err := NewOKVSBK(n, okvs.WithSeed(testSeed), okvs.WithBandWidth(600))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("band", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := r.Encode(kvs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("bytes", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, ok := encodeBytes(r, kvs); !ok {
+				b.Fatal("the byte-wise elimination failed")
+			}
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package ourf2
+
+import (
+	"math/bits"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/band"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// solve hashes the N keys, eliminates once and back-substitutes k columns of
+// uint32 values at the same time, packed two to a word: column c sits in
+// bits [32(c%2), 32(c%2)+32) of word c/2. value(i, dst) packs the values of
+// key i into dst. It returns the packed P, M slots of (k+1)/2 words.
+func (r *OKVSBK) solve(key func(i int) []byte, k int, value func(i int, dst []uint64)) ([]uint64, error) {
+	vw := (k + 1) / 2
+	s := r.system(key, vw)
+	common.ParallelFor(r.N, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			value(s.Idx[i], s.Val(i))
+		}
+	})
+	piv, fail := s.Eliminate()
+	if fail >= 0 {
+		return nil, &okvs.SingularError{Row: fail, Key: key(s.Idx[fail])}
+	}
+	p := make([]uint64, r.M*vw)
+	if r.Rand != nil {
+		if err := common.FillWords(r.Rand, p, vw, 32*k, piv); err != nil {
+			return nil, err
+		}
+	}
+	s.BackSubstitute(piv, p)
+	return p, nil
+}
+
+// system 计算每个 key 的起始位置和行，返回按位置排好序、还没有填 value 的系统
+func (r *OKVSBK) system(key func(i int) []byte, vw int) *band.Solver {
+	pos := make([]int, r.N)
+	common.ParallelFor(r.N, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			pos[i] = r.pos(key(i))
+		}
+	})
+	s := band.New(r.M, r.W, vw, pos)
+	common.ParallelFor(r.N, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			band.SetBytes(s.Row(i), r.hash2(key(s.Idx[i])), r.W)
+		}
+	})
+	return s
+}
+
+// EncodeColumns encodes several value vectors over the same keys: values[c][i]
+// is the value of keys[i] in column c. Hashing, sorting and elimination run
+// once for all columns, and the result holds one P per column. r.P is left
+// untouched; to decode column c, use a copy of r with P set to the c-th
+// result, or DecodeColumns.
+func (r *OKVSBK) EncodeColumns(keys [][]byte, values [][]uint32) ([][]uint32, error) {
+	if len(keys) != r.N {
+		return nil, common.SizeMismatch(r.N, len(keys))
+	}
+	for c := range values {
+		if len(values[c]) != r.N {
+			return nil, common.SizeMismatch(r.N, len(values[c]))
+		}
+	}
+	k := len(values)
+	if k == 0 {
+		return nil, nil
+	}
+	p, err := r.solve(func(i int) []byte { return keys[i] }, k, func(i int, dst []uint64) {
+		for t := range dst {
+			dst[t] = 0
+		}
+		for c := range values {
+			dst[c/2] |= uint64(values[c][i]) << (32 * (c % 2))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	vw := (k + 1) / 2
+	ps := make([][]uint32, k)
+	for c := range ps {
+		ps[c] = make([]uint32, r.M)
+		for j := range ps[c] {
+			ps[c][j] = uint32(p[j*vw+c/2] >> (32 * (c % 2)))
+		}
+	}
+	return ps, nil
+}
+
+// DecodeColumns 用同一次 hash 解码 key 在每个 ps[c] 中的 value
+func (r *OKVSBK) DecodeColumns(key []byte, ps [][]uint32) []uint32 {
+	pos := r.pos(key)
+	row := r.hash2(key)
+	res := make([]uint32, len(ps))
+	for t, b := range row {
+		for b != 0 {
+			j := bits.LeadingZeros8(b)
+			b &^= 0x80 >> j
+			for c := range ps {
+				res[c] ^= ps[c][pos+8*t+j]
+			}
+		}
+	}
+	return res
+}
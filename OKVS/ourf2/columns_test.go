@@ -0,0 +1,58 @@
+package ourf2
+
+import (
+	mrand "math/rand"
+	"reflect"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+// 列数取奇数时最后一个 word 只用了低 32 位，取偶数时两半都用上，两种打包都要覆盖
+func TestEncodeColumns(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(7))
+	keys := testKeys(rng, testN)
+	for _, k := range []int{1, 2, 3, 4, 5} {
+		r, err := NewOKVSBK(testN, okvs.WithSeed(testSeed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := make([][]uint32, k)
+		for c := range values {
+			values[c] = make([]uint32, testN)
+			for i := range values[c] {
+				values[c][i] = rng.Uint32()
+			}
+		}
+		ps, err := r.EncodeColumns(keys, values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ps) != k {
+			t.Fatalf("k = %d: got %d columns", k, len(ps))
+		}
+		for i, key := range keys {
+			got := r.DecodeColumns(key, ps)
+			for c := range got {
+				if got[c] != values[c][i] {
+					t.Fatalf("k = %d: DecodeColumns(key %d)[%d]: got %d, want %d", k, i, c, got[c], values[c][i])
+				}
+			}
+		}
+		// 没有随机填充时解是唯一确定的，打包在一起的每一列都应该和单独编码的结果相同
+		for c := range values {
+			single, err := r.EncodeColumns(keys, values[c:c+1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ps[c], single[0]) {
+				t.Fatalf("k = %d: column %d differs from encoding it alone", k, c)
+			}
+			q := *r
+			q.P = ps[c]
+			if got := q.Decode(keys[c]); got != values[c][c] {
+				t.Fatalf("k = %d: Decode with P = ps[%d]: got %d, want %d", k, c, got, values[c][c])
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package ourf2
+
+import (
+	"errors"
+	mrand "math/rand"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+func TestDecodeBatch(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(5))
+	keys := testKeys(rng, testN)
+	r, _ := encodeBK(t, rng, keys, okvs.WithWorkers(3))
+
+	out := make([]uint32, len(keys))
+	if err := r.DecodeBatch(keys, out); err != nil {
+		t.Fatal(err)
+	}
+	for i, key := range keys {
+		if want := r.Decode(key); out[i] != want {
+			t.Fatalf("DecodeBatch[%d]: got %d, want %d", i, out[i], want)
+		}
+	}
+	if err := r.DecodeBatch(keys, out[:len(keys)-1]); !errors.Is(err, okvs.ErrSizeMismatch) {
+		t.Fatalf("short out: got %v, want ErrSizeMismatch", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(6))
+	keys := testKeys(rng, testN)
+	r, vals := encodeBK(t, rng, keys)
+	kvs := make([]KVBK, len(keys))
+	for i := range kvs {
+		kvs[i] = KVBK{Key: keys[i], Value: vals[i]}
+	}
+	if bad := r.Verify(kvs); bad != nil {
+		t.Fatalf("Verify: got %v, want nil", bad)
+	}
+
+	const tampered = 417
+	kvs[tampered].Value ^= 1
+	bad := r.Verify(kvs)
+	if len(bad) != 1 || bad[0].Index != tampered || bad[0].Got != vals[tampered] || bad[0].Want != kvs[tampered].Value {
+		t.Fatalf("Verify: got %+v, want index %d", bad, tampered)
+	}
+}
@@ -0,0 +1,175 @@
+package ourf2
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/band"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// Encoder builds the P of an OKVSBK from pairs added one at a time, for key
+// sets too large to hold as a []KVBK next to the system built from it. Add
+// hashes the key at once and keeps only its start position, packed band and
+// value in an arena sized for N rows up front; the key itself is not
+// retained. Finish sorts the arena in place and solves it, so no second
+// per-key structure is ever allocated.
+//
+// Add, AddSeq and AddChan may be called from several goroutines. Finish must
+// only be called once all of them have returned.
+type Encoder struct {
+	r    *OKVSBK
+	rw   int      // 每一行的 word 数
+	pos  []int    // 按加入顺序的起始位置
+	rows []uint64 // 按加入顺序打包的行，每行 rw 个 word
+	vals []uint64
+	n    atomic.Int64 // 已经领取的槽位数
+	done atomic.Bool
+	peak int64
+}
+
+// NewEncoder returns an Encoder whose Finish writes r.P. r.P is not touched
+// before that.
+func (r *OKVSBK) NewEncoder() *Encoder {
+	rw := (r.W + 63) / 64
+	e := &Encoder{
+		r:    r,
+		rw:   rw,
+		pos:  make([]int, r.N),
+		rows: make([]uint64, r.N*rw),
+		vals: make([]uint64, r.N),
+	}
+	e.peak = e.arenaBytes()
+	return e
+}
+
+// arenaBytes 是 pos、rows 和 vals 占的字节数
+func (e *Encoder) arenaBytes() int64 {
+	return 8 * int64(e.r.N) * int64(e.rw+2)
+}
+
+// Add stores value under key. Keys beyond the N-th fail with
+// ErrSizeMismatch without taking a slot, so Finish still succeeds with the
+// first N, and any key after Finish fails with ErrFinished.
+func (e *Encoder) Add(key []byte, value uint32) error {
+	if e.done.Load() {
+		return okvs.ErrFinished
+	}
+	i, err := e.claim()
+	if err != nil {
+		return err
+	}
+	e.pos[i] = e.r.pos(key)
+	band.SetBytes(e.rows[i*e.rw:(i+1)*e.rw], e.r.hash2(key), e.r.W)
+	e.vals[i] = uint64(value)
+	return nil
+}
+
+// claim 领取下一个空槽位，已满时不增加计数
+func (e *Encoder) claim() (int, error) {
+	for {
+		n := e.n.Load()
+		if n >= int64(e.r.N) {
+			return 0, common.SizeMismatch(e.r.N, int(n)+1)
+		}
+		if e.n.CompareAndSwap(n, n+1) {
+			return int(n), nil
+		}
+	}
+}
+
+// AddSeq adds every pair seq yields and stops at the first error. seq has
+// the shape of iter.Seq2[[]byte, uint32].
+func (e *Encoder) AddSeq(seq func(yield func(key []byte, value uint32) bool)) error {
+	var err error
+	seq(func(key []byte, value uint32) bool {
+		err = e.Add(key, value)
+		return err == nil
+	})
+	return err
+}
+
+// AddChan adds every pair received from ch until ch is closed, hashing with
+// r.Workers goroutines (GOMAXPROCS when 0). After an error it keeps draining
+// ch so the sender is not blocked, and returns the first error.
+func (e *Encoder) AddChan(ch <-chan KVBK) error {
+	workers := e.r.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for kv := range ch {
+				if err := e.Add(kv.Key, kv.Value); err != nil {
+					once.Do(func() { first = err })
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return first
+}
+
+// Len 返回已经加入的 key 数
+func (e *Encoder) Len() int {
+	return int(e.n.Load())
+}
+
+// Finish solves the system once exactly N keys have been added and writes
+// the result to r.P. With fewer keys it fails with ErrSizeMismatch and more
+// may still be added. Any other outcome, including a singular system, ends
+// the Encoder: the rows are eliminated in place, so a retry with a new Seed
+// has to add the keys again. A SingularError from Finish has no Key.
+func (e *Encoder) Finish() (*OKVSBK, error) {
+	r := e.r
+	if n := int(e.n.Load()); n != r.N {
+		return nil, common.SizeMismatch(r.N, n)
+	}
+	if e.done.Swap(true) {
+		return nil, okvs.ErrFinished
+	}
+	arena := e.arenaBytes()
+	// 排序时多出 Idx、目标位置和计数数组
+	e.note(arena + 16*int64(r.N) + 8*int64(r.M+1))
+	s := band.Adopt(r.M, r.W, 1, e.pos, e.rows, e.vals)
+	e.pos, e.rows, e.vals = nil, nil, nil
+
+	piv, fail := s.Eliminate()
+	if fail >= 0 {
+		return nil, &okvs.SingularError{Row: fail}
+	}
+	// 回代时多出 Idx、主元和 P 的 word
+	e.note(arena + 16*int64(r.N) + 8*int64(r.M))
+	p := make([]uint64, r.M)
+	if r.Rand != nil {
+		if err := common.FillWords(r.Rand, p, 1, 32, piv); err != nil {
+			return nil, err
+		}
+	}
+	s.BackSubstitute(piv, p)
+	for j := range r.P {
+		r.P[j] = uint32(p[j])
+	}
+	return r, nil
+}
+
+func (e *Encoder) note(bytes int64) {
+	e.peak = max(e.peak, bytes)
+}
+
+// PeakBytes reports the most memory the Encoder's own buffers held at once:
+// the arena while keys are added, plus the sort's index arrays and then the
+// pivots and solution words during Finish. It leaves out r.P and whatever
+// the caller keeps, and is exact rather than sampled from the runtime.
+func (e *Encoder) PeakBytes() int64 {
+	return e.peak
+}
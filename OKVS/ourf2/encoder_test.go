@@ -0,0 +1,196 @@
+package ourf2
+
+import (
+	"errors"
+	"io"
+	mrand "math/rand"
+	"slices"
+	"sync"
+	"testing"
+	"unsafe"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+const encN = 1 << 14
+
+// newRand 返回 seed 决定的随机源，nil 表示不随机填充
+func newRand(seed int64) io.Reader {
+	if seed == 0 {
+		return nil
+	}
+	return mrand.New(mrand.NewSource(seed))
+}
+
+// TestEncodePathsAgree 检查同一组 key 和 value 经过 Encode、Encoder、
+// Prepare + Solver.Encode 和 EncodeColumns 得到完全相同的 P
+func TestEncodePathsAgree(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(3))
+	keys := testKeys(rng, encN)
+	vals := make([]uint32, encN)
+	other := make([]uint32, encN)
+	kvs := make([]KVBK, encN)
+	for i := range kvs {
+		vals[i], other[i] = rng.Uint32(), rng.Uint32()
+		kvs[i] = KVBK{Key: keys[i], Value: vals[i]}
+	}
+	for _, bitPos := range []bool{false, true} {
+		for _, seed := range []int64{0, 7} {
+			mk := func() *OKVSBK {
+				r, err := NewOKVSBK(encN, okvs.WithSeed(testSeed), okvs.WithBitPositions(bitPos), okvs.WithRand(newRand(seed)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return r
+			}
+			ref := mk()
+			if _, err := ref.Encode(kvs); err != nil {
+				t.Fatal(err)
+			}
+
+			r := mk()
+			e := r.NewEncoder()
+			for _, kv := range kvs {
+				if err := e.Add(kv.Key, kv.Value); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := e.Finish(); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(r.P, ref.P) {
+				t.Errorf("BitPos %v, rand %d: Encoder P differs from Encode", bitPos, seed)
+			}
+
+			sv, err := mk().Prepare(keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sv.Rand = newRand(seed)
+			p, err := sv.Encode(vals)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(p, ref.P) {
+				t.Errorf("BitPos %v, rand %d: Solver.Encode P differs from Encode", bitPos, seed)
+			}
+
+			ps, err := mk().EncodeColumns(keys, [][]uint32{vals})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(ps[0], ref.P) {
+				t.Errorf("BitPos %v, rand %d: EncodeColumns P differs from Encode", bitPos, seed)
+			}
+		}
+
+		// 没有随机填充时，多列的每一列都和单独编码那一列相同
+		r, _ := NewOKVSBK(encN, okvs.WithSeed(testSeed), okvs.WithBitPositions(bitPos))
+		ps, err := r.EncodeColumns(keys, [][]uint32{vals, other})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sv, err := r.Prepare(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for c, v := range [][]uint32{vals, other} {
+			p, err := sv.Encode(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(ps[c], p) {
+				t.Errorf("BitPos %v: column %d of EncodeColumns differs from Solver.Encode", bitPos, c)
+			}
+		}
+	}
+}
+
+// slicePathBytes 是 Encode 消元时至少同时持有的字节数：调用方的 []KVBK 和 key，
+// 起始位置，排好序的 Pos、Idx、打包的行和 value，以及回代的 P 和主元
+func slicePathBytes(r *OKVSBK, kvs []KVBK) int64 {
+	n, m := int64(r.N), int64(r.M)
+	rw := int64(r.W+63) / 64
+	b := int64(unsafe.Sizeof(KVBK{})) * n
+	for _, kv := range kvs {
+		b += int64(len(kv.Key))
+	}
+	return b + 8*n*(1+2+rw+1) + 8*(m+n)
+}
+
+func TestEncoderPeakBytes(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(4))
+	keys := testKeys(rng, encN)
+	kvs := make([]KVBK, encN)
+	for i := range kvs {
+		kvs[i] = KVBK{Key: keys[i], Value: rng.Uint32()}
+	}
+	r, _ := NewOKVSBK(encN, okvs.WithSeed(testSeed))
+	e := r.NewEncoder()
+	if err := e.AddSeq(func(yield func([]byte, uint32) bool) {
+		for _, kv := range kvs {
+			if !yield(kv.Key, kv.Value) {
+				return
+			}
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	peak, slice := e.PeakBytes(), slicePathBytes(r, kvs)
+	t.Logf("Encoder peak %d bytes, slice-based Encode at least %d bytes", peak, slice)
+	if peak >= slice {
+		t.Fatalf("Encoder peak of %d bytes is not below the %d bytes the slice-based path holds", peak, slice)
+	}
+}
+
+func TestEncoderConcurrentAdd(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(5))
+	keys := testKeys(rng, encN)
+	kvs := make([]KVBK, encN)
+	for i := range kvs {
+		kvs[i] = KVBK{Key: keys[i], Value: rng.Uint32()}
+	}
+	r, _ := NewOKVSBK(encN, okvs.WithSeed(testSeed))
+	e := r.NewEncoder()
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < encN; i += workers {
+				if err := e.Add(kvs[i].Key, kvs[i].Value); err != nil {
+					errs[w] = err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal(err)
+	}
+	if e.Len() != encN {
+		t.Fatalf("Len = %d, want %d", e.Len(), encN)
+	}
+	// 多出来的 key 报错，但不占槽位，Finish 仍然成功
+	if err := e.Add([]byte("one too many"), 1); !errors.Is(err, okvs.ErrSizeMismatch) {
+		t.Fatalf("Add past N: got %v, want ErrSizeMismatch", err)
+	}
+	if _, err := e.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if ms := r.Verify(kvs); ms != nil {
+		t.Fatalf("%d of %d keys decode wrongly, first %+v", len(ms), encN, ms[0])
+	}
+	if err := e.Add(keys[0], 0); !errors.Is(err, okvs.ErrFinished) {
+		t.Fatalf("Add after Finish: got %v, want ErrFinished", err)
+	}
+	if _, err := e.Finish(); !errors.Is(err, okvs.ErrFinished) {
+		t.Fatalf("second Finish: got %v, want ErrFinished", err)
+	}
+}
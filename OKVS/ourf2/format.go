@@ -0,0 +1,139 @@
+package ourf2
+
+import (
+	"io"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+func init() {
+	okvs.RegisterLoader(okvs.VariantOKVSBK, func(h *okvs.Header, payload []byte) (okvs.Store, error) {
+		r, err := okvsbkFrom(h, payload)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+	okvs.RegisterLoader(okvs.VariantOKVSBKW, func(h *okvs.Header, payload []byte) (okvs.Store, error) {
+		r, err := okvsbkwFrom(h, payload)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+func bitPosFlag(on bool) uint8 {
+	if on {
+		return okvs.FlagBitPos
+	}
+	return 0
+}
+
+// WriteTo writes r as an okvs container with 4-byte values. Rand is not
+// stored.
+func (r *OKVSBK) WriteTo(w io.Writer) (int64, error) {
+	h := &okvs.Header{
+		Variant: okvs.VariantOKVSBK,
+		Flags:   bitPosFlag(r.BitPos) | okvs.HashFlag(r.Hash),
+		N:       r.N,
+		M:       r.M,
+		W:       r.W,
+		R:       r.R,
+		Seed:    r.Seed,
+		Tag:     r.Tag,
+	}
+	return common.WriteUint32s(w, h, r.P)
+}
+
+// ReadOKVSBK 读取 OKVSBK.WriteTo 写的容器
+func ReadOKVSBK(rd io.Reader) (*OKVSBK, error) {
+	h, payload, err := okvs.ReadContainer(rd)
+	if err != nil {
+		return nil, err
+	}
+	return okvsbkFrom(h, payload)
+}
+
+func okvsbkFrom(h *okvs.Header, payload []byte) (*OKVSBK, error) {
+	p, err := common.Uint32Payload(h, okvs.VariantOKVSBK, payload)
+	if err != nil {
+		return nil, err
+	}
+	r := &OKVSBK{
+		N:      h.N,
+		M:      h.M,
+		W:      h.W,
+		B:      h.W / 8,
+		R:      h.R,
+		P:      p,
+		Seed:   h.Seed,
+		Tag:    h.Tag,
+		BitPos: h.Flags&okvs.FlagBitPos != 0,
+		Hash:   h.HashMode(),
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// WriteTo writes r as an okvs container with L-byte values, each slot in
+// the little-endian layout of PackValue. Rand is not stored.
+func (r *OKVSBKW) WriteTo(w io.Writer) (int64, error) {
+	h := &okvs.Header{
+		Variant:    okvs.VariantOKVSBKW,
+		Flags:      bitPosFlag(r.BitPos) | okvs.HashFlag(r.Hash),
+		ValueWidth: r.L,
+		N:          r.N,
+		M:          r.M,
+		W:          r.W,
+		R:          r.R,
+		Seed:       r.Seed,
+		Tag:        r.Tag,
+	}
+	payload := make([]byte, r.M*r.L)
+	for j := 0; j < r.M; j++ {
+		UnpackValue(payload[j*r.L:(j+1)*r.L], r.P[j*r.VW:(j+1)*r.VW])
+	}
+	return okvs.WriteContainer(w, h, payload)
+}
+
+// ReadOKVSBKW 读取 OKVSBKW.WriteTo 写的容器
+func ReadOKVSBKW(rd io.Reader) (*OKVSBKW, error) {
+	h, payload, err := okvs.ReadContainer(rd)
+	if err != nil {
+		return nil, err
+	}
+	return okvsbkwFrom(h, payload)
+}
+
+func okvsbkwFrom(h *okvs.Header, payload []byte) (*OKVSBKW, error) {
+	if err := h.Expect(okvs.VariantOKVSBKW, -1, payload); err != nil {
+		return nil, err
+	}
+	l := h.ValueWidth
+	vw := (l + 7) / 8
+	r := &OKVSBKW{
+		N:      h.N,
+		M:      h.M,
+		W:      h.W,
+		B:      h.W / 8,
+		R:      h.R,
+		L:      l,
+		VW:     vw,
+		P:      make([]uint64, h.M*vw),
+		Seed:   h.Seed,
+		Tag:    h.Tag,
+		BitPos: h.Flags&okvs.FlagBitPos != 0,
+		Hash:   h.HashMode(),
+	}
+	for j := 0; j < h.M; j++ {
+		PackValue(r.P[j*vw:(j+1)*vw], payload[j*l:(j+1)*l])
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,86 @@
+package ourf2
+
+import (
+	"fmt"
+	mrand "math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// legacyKV 是 testdata/okvsbk_legacy.bin 编码的第 i 个 key 和 value。这个文件由
+// 加入标志字节之前的 SerializeOKVSBK 写出，参数是 N=100、M=160、W=64、R=96
+func legacyKV(i int) KVBK {
+	return KVBK{Key: []byte(fmt.Sprintf("legacy-key-%03d", i)), Value: uint32(i) * 2654435761}
+}
+
+func TestDeserializeLegacyHash32(t *testing.T) {
+	r, err := DeserializeOKVSBK("testdata/okvsbk_legacy.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Hash != okvs.Hash32 || r.BitPos {
+		t.Fatalf("Hash %v, BitPos %v: want Hash32 without BitPos for a file without the flag byte", r.Hash, r.BitPos)
+	}
+	if r.N != 100 || r.M != 160 || r.W != 64 || r.R != 96 {
+		t.Fatalf("N, M, W, R = %d, %d, %d, %d, want 100, 160, 64, 96", r.N, r.M, r.W, r.R)
+	}
+	for i := 0; i < r.N; i++ {
+		kv := legacyKV(i)
+		if got := r.Decode(kv.Key); got != kv.Value {
+			t.Fatalf("Decode(%q) = %#x, want %#x", kv.Key, got, kv.Value)
+		}
+	}
+}
+
+func TestSerializeHash64Flag(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(6))
+	keys := testKeys(rng, testN)
+	for _, bitPos := range []bool{false, true} {
+		// 旧格式不保存 Seed，所以不带种子编码
+		r, err := NewOKVSBK(testN, okvs.WithBitPositions(bitPos), okvs.WithRand(rng))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Hash != okvs.Hash64 {
+			t.Fatalf("default Hash is %v, want Hash64", r.Hash)
+		}
+		kvs := make([]KVBK, testN)
+		for i := range kvs {
+			kvs[i] = KVBK{Key: keys[i], Value: rng.Uint32()}
+		}
+		if _, err := r.Encode(kvs); err != nil {
+			t.Fatal(err)
+		}
+
+		name := filepath.Join(t.TempDir(), "okvsbk.bin")
+		if err := SerializeOKVSBK(name, *r); err != nil {
+			t.Fatal(err)
+		}
+		raw, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := byte(common.LegacyHash64)
+		if bitPos {
+			want |= common.LegacyBitPos
+		}
+		if got := raw[len(raw)-1]; len(raw) != 24+4*len(r.P)+1 || got != want {
+			t.Fatalf("BitPos %v: %d bytes ending in %#x, want %d bytes ending in flag %#x", bitPos, len(raw), got, 24+4*len(r.P)+1, want)
+		}
+
+		d, err := DeserializeOKVSBK(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Hash != okvs.Hash64 || d.BitPos != bitPos {
+			t.Fatalf("round trip: Hash %v, BitPos %v, want Hash64, BitPos %v", d.Hash, d.BitPos, bitPos)
+		}
+		if ms := d.Verify(kvs); ms != nil {
+			t.Fatalf("BitPos %v: %d of %d keys decode wrongly after the round trip, first %+v", bitPos, len(ms), testN, ms[0])
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package ourf2
+
+import (
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// MappedOKVSBK is a read-only OKVSBK whose P is read straight from a
+// memory-mapped file, so opening a table copies nothing and every process
+// mapping the same file shares its pages. It only offers lookups and, like
+// Decode, they may run concurrently. After Close it must not be used.
+type MappedOKVSBK struct {
+	t *common.MappedTable[*OKVSBK]
+}
+
+// OpenMapped maps a file written by OKVSBK.WriteTo or by the legacy
+// SerializeOKVSBK. The container digest is not checked, so opening does not
+// touch P; call CheckDigest for that. Where mmap is unavailable the file is
+// read into memory instead, and where P in the file is not aligned for this
+// host P is decoded into memory; Mapped reports which one happened.
+func OpenMapped(filename string) (*MappedOKVSBK, error) {
+	m, err := common.Map(filename)
+	if err != nil {
+		return nil, err
+	}
+	return newMapped(m)
+}
+
+// newMapped 从 m 解析 OKVSBK，失败时关闭 m
+func newMapped(m *common.Mapping) (*MappedOKVSBK, error) {
+	t, err := common.NewMappedTable(m, okvsbkFrom, okvsbkFromLegacy, func(r *OKVSBK) []uint32 { return r.P })
+	if err != nil {
+		return nil, err
+	}
+	return &MappedOKVSBK{t: t}, nil
+}
+
+// Decode 和 OKVSBK.Decode 相同
+func (m *MappedOKVSBK) Decode(key []byte) uint32 {
+	return m.t.T.Decode(key)
+}
+
+// DecodewithCheck 和 OKVSBK.DecodewithCheck 相同
+func (m *MappedOKVSBK) DecodewithCheck(key []byte) (uint32, bool) {
+	return m.t.T.DecodewithCheck(key)
+}
+
+// DecodeBatch 和 OKVSBK.DecodeBatch 相同
+func (m *MappedOKVSBK) DecodeBatch(keys [][]byte, out []uint32) error {
+	return m.t.T.DecodeBatch(keys, out)
+}
+
+// Verify 和 OKVSBK.Verify 相同
+func (m *MappedOKVSBK) Verify(kvs []KVBK) []okvs.Mismatch {
+	return m.t.T.Verify(kvs)
+}
+
+// Params 返回文件里的参数
+func (m *MappedOKVSBK) Params() okvs.Params {
+	r := m.t.T
+	return okvs.Params{N: r.N, M: r.M, W: r.W, R: r.R}
+}
+
+// Mapped 返回 Decode 是否直接读映射的文件
+func (m *MappedOKVSBK) Mapped() bool {
+	return m.t.Mapped()
+}
+
+// CheckDigest 检查容器末尾的摘要，会读一遍整个文件；旧格式没有摘要，总是返回 nil
+func (m *MappedOKVSBK) CheckDigest() error {
+	return m.t.CheckDigest()
+}
+
+// Close 解除映射
+func (m *MappedOKVSBK) Close() error {
+	return m.t.Close()
+}
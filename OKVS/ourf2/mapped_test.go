@@ -0,0 +1,120 @@
+package ourf2
+
+import (
+	"errors"
+	mrand "math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// writeTable 把 r 用 WriteTo 写到临时目录，返回文件名
+func writeTable(t *testing.T, r *OKVSBK) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "okvsbk")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.WriteTo(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestOpenMapped(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(7))
+	keys := testKeys(rng, testN)
+	r, vals := encodeBK(t, rng, keys)
+	kvs := make([]KVBK, testN)
+	for i := range kvs {
+		kvs[i] = KVBK{Key: keys[i], Value: vals[i]}
+	}
+	name := writeTable(t, r)
+
+	read, err := common.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback, err := newMapped(read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenMapped(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// mmap 只在 Linux 上一定可用，其他平台可能也走读文件的路径
+	if runtime.GOOS == "linux" && !m.Mapped() {
+		t.Error("OpenMapped did not map the container on linux")
+	}
+	if fallback.Mapped() {
+		t.Error("a table read into memory reports Mapped")
+	}
+	for name, mt := range map[string]*MappedOKVSBK{"mapped": m, "read": fallback} {
+		if ms := mt.Verify(kvs); ms != nil {
+			t.Errorf("%s: %d of %d keys decode wrongly, first %+v", name, len(ms), testN, ms[0])
+		}
+		if got, want := mt.Params(), (okvs.Params{N: r.N, M: r.M, W: r.W, R: r.R}); got != want {
+			t.Errorf("%s: Params = %+v, want %+v", name, got, want)
+		}
+		if err := mt.CheckDigest(); err != nil {
+			t.Errorf("%s: CheckDigest: %v", name, err)
+		}
+		if err := mt.Close(); err != nil {
+			t.Errorf("%s: Close: %v", name, err)
+		}
+		if err := mt.Close(); err != nil {
+			t.Errorf("%s: second Close: %v", name, err)
+		}
+		if mt.Mapped() {
+			t.Errorf("%s: Mapped after Close", name)
+		}
+	}
+
+	// 打开时不检查摘要，改动 P 之后 CheckDigest 才会发现
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[len(b)-okvs.DigestSize-1] ^= 1
+	if err := os.WriteFile(name, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err = OpenMapped(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if err := m.CheckDigest(); !errors.Is(err, okvs.ErrChecksum) {
+		t.Fatalf("CheckDigest of a modified file: got %v, want ErrChecksum", err)
+	}
+
+	if _, err := OpenMapped(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("OpenMapped of a missing file: got %v, want ErrNotExist", err)
+	}
+}
+
+func TestOpenMappedLegacy(t *testing.T) {
+	m, err := OpenMapped("testdata/okvsbk_legacy.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	for i := 0; i < m.Params().N; i++ {
+		kv := legacyKV(i)
+		if got := m.Decode(kv.Key); got != kv.Value {
+			t.Fatalf("Decode(%q) = %#x, want %#x", kv.Key, got, kv.Value)
+		}
+	}
+	if err := m.CheckDigest(); err != nil {
+		t.Fatalf("CheckDigest of a legacy file: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package ourf2
+
+import (
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/OurOKVS/OKVS/linear"
+)
+
+// Matrix 返回 keys 对应的解码矩阵，linear.Mul(a, r.P)[i] == r.Decode(keys[i])
+func (r *OKVSBK) Matrix(keys [][]byte) *linear.F2 {
+	a := &linear.F2{N: len(keys), M: r.M, W: r.W, Pos: make([]int, len(keys)), Rows: make([][]byte, len(keys))}
+	common.ParallelFor(len(keys), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			a.Pos[i] = r.pos(keys[i])
+			a.Rows[i] = r.hash2(keys[i])
+		}
+	})
+	return a
+}
+
+// Matrix returns the decode map of r over all VW words of every slot: the
+// band of each key spread with stride VW, i.e. the Kronecker product of the
+// key matrix with the VW×VW identity. It has len(keys)*VW rows and M*VW
+// columns, and linear.Mul(a, r.P)[i*VW+v] is word v of
+// r.DecodeWords(keys[i]).
+func (r *OKVSBKW) Matrix(keys [][]byte) *linear.F2 {
+	vw := r.VW
+	n := len(keys) * vw
+	a := &linear.F2{N: n, M: r.M * vw, W: r.W * vw, Pos: make([]int, n), Rows: make([][]byte, n)}
+	common.ParallelFor(len(keys), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			pos, band := r.pos(keys[i]), r.hash2(keys[i])
+			for v := 0; v < vw; v++ {
+				// 第 j 位移到 j*VW+v，行内 bit 按字节从高位开始编号
+				row := make([]byte, r.W*vw/8)
+				for j := 0; j < r.W; j++ {
+					if band[j/8]&(0x80>>(j%8)) != 0 {
+						b := j*vw + v
+						row[b/8] |= 0x80 >> (b % 8)
+					}
+				}
+				a.Pos[i*vw+v] = pos * vw
+				a.Rows[i*vw+v] = row
+			}
+		}
+	})
+	return a
+}
@@ -0,0 +1,52 @@
+package ourf2
+
+import (
+	mrand "math/rand"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/linear"
+)
+
+// Matrix 和 Decode 对任意的 P 都应该一致，所以这里直接用随机的 P，不需要编码
+func TestMatrix(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(8))
+	keys := testKeys(rng, testN)
+	for _, bitPos := range []bool{false, true} {
+		r, err := NewOKVSBK(len(keys), okvs.WithSeed(testSeed), okvs.WithBitPositions(bitPos))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range r.P {
+			r.P[j] = rng.Uint32()
+		}
+		got := linear.Mul(r.Matrix(keys), r.P)
+		for i, key := range keys {
+			if want := r.Decode(key); got[i] != want {
+				t.Fatalf("BitPos = %v: Mul(Matrix, P)[%d] = %d, want %d", bitPos, i, got[i], want)
+			}
+		}
+	}
+}
+
+func TestMatrixWide(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(9))
+	keys := testKeys(rng, testN)
+	r, err := NewOKVSBKW(len(keys), okvs.WithSeed(testSeed), okvs.WithValueSize(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := range r.P {
+		r.P[j] = rng.Uint64()
+	}
+	got := linear.Mul(r.Matrix(keys), r.P)
+	want := make([]uint64, r.VW)
+	for i, key := range keys {
+		r.DecodeWords(want, key)
+		for v := range want {
+			if got[i*r.VW+v] != want[v] {
+				t.Fatalf("Mul(Matrix, P)[%d] = %#x, want word %d of DecodeWords = %#x", i*r.VW+v, got[i*r.VW+v], v, want[v])
+			}
+		}
+	}
+}
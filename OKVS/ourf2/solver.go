@@ -0,0 +1,211 @@
+package ourf2
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/band"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// Solver is the triangulation of a fixed key set, made once by
+// OKVSBK.Prepare. It records the sorted rows in echelon form, the pivots and
+// every row operation of the elimination, so Encode only replays the value
+// updates and back-substitutes. A P produced by Encode decodes with the
+// OKVSBK the Solver was prepared from (same Seed, Tag, BitPos and Hash).
+type Solver struct {
+	N    int       //okvs存储的k-v长度
+	M    int       //okvs的实际长度
+	W    int       //随机块的长度
+	Rand io.Reader // 非空时用它随机填充非主元位置，不会被序列化
+
+	s   *band.Solver
+	piv []int
+}
+
+// Prepare 对 keys 做一次消元并记录下来，之后可以用 Solver.Encode 反复编码新的 value
+func (r *OKVSBK) Prepare(keys [][]byte) (*Solver, error) {
+	if len(keys) != r.N {
+		return nil, common.SizeMismatch(r.N, len(keys))
+	}
+	s := r.system(func(i int) []byte { return keys[i] }, 1)
+	s.Record = true
+	piv, fail := s.Eliminate()
+	if fail >= 0 {
+		return nil, &okvs.SingularError{Row: fail, Key: keys[s.Idx[fail]]}
+	}
+	s.Record = false
+	s.Vals = nil
+	return &Solver{N: r.N, M: r.M, W: r.W, Rand: r.Rand, s: s, piv: piv}, nil
+}
+
+// Encode returns P for values[i] stored under the i-th prepared key. It does
+// not modify the Solver, so several goroutines may call it at once.
+func (sv *Solver) Encode(values []uint32) ([]uint32, error) {
+	if len(values) != sv.N {
+		return nil, common.SizeMismatch(sv.N, len(values))
+	}
+	s := *sv.s
+	s.Vals = make([]uint64, s.N)
+	for i := range s.Vals {
+		s.Vals[i] = uint64(values[s.Idx[i]])
+	}
+	s.Replay()
+	p := make([]uint64, sv.M)
+	if sv.Rand != nil {
+		if err := common.FillWords(sv.Rand, p, 1, 32, sv.piv); err != nil {
+			return nil, err
+		}
+	}
+	s.BackSubstitute(sv.piv, p)
+	res := make([]uint32, sv.M)
+	for j := range p {
+		res[j] = uint32(p[j])
+	}
+	return res, nil
+}
+
+// maxSolverExpansion 限制读入的 Solver 的 M：M 超过 W + maxSolverExpansion·N 的文件被拒绝，
+// 否则很短的文件就能让 Encode 分配接近 2^63 字节
+const maxSolverExpansion = 64
+
+// solverChunk 是 ReadSolver 一次分配的最多元素个数
+const solverChunk = 1 << 16
+
+// WriteTo 把 Solver 写到 w：int64 的 N、M、W、RW，按行排好序的原始下标、起始位置和主元，
+// 行操作的个数和列表，最后是消元后的行，全部为小端。Rand 不会被写入
+func (sv *Solver) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	cw := &countingWriter{w: bw}
+	s := sv.s
+	idx := make([]int64, s.N)
+	pos := make([]int64, s.N)
+	piv := make([]int64, s.N)
+	for i := 0; i < s.N; i++ {
+		idx[i], pos[i], piv[i] = int64(s.Idx[i]), int64(s.Pos[i]), int64(sv.piv[i])
+	}
+	ops := make([]int64, len(s.Ops))
+	for t, v := range s.Ops {
+		ops[t] = int64(v)
+	}
+	for _, v := range []any{
+		[]int64{int64(sv.N), int64(sv.M), int64(sv.W), int64(s.RW)},
+		idx, pos, piv,
+		int64(len(ops)), ops,
+		s.Rows,
+	} {
+		if err := binary.Write(cw, binary.LittleEndian, v); err != nil {
+			return cw.n, err
+		}
+	}
+	return cw.n, bw.Flush()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	k, err := c.w.Write(p)
+	c.n += int64(k)
+	return k, err
+}
+
+// ReadSolver 读取 Solver.WriteTo 写的数据并检查各字段是否一致。数组的长度来自输入，
+// 所以按块读入，截断或伪造的输入在分配超过实际读到的字节之前就会失败
+func ReadSolver(rd io.Reader) (*Solver, error) {
+	rd = bufio.NewReader(rd)
+	head, err := readInts(rd, 4)
+	if err != nil {
+		return nil, err
+	}
+	n, m, w, rw := head[0], head[1], head[2], head[3]
+	if n <= 0 || n > m || w <= 0 || w > m || rw != (w+63)/64 {
+		return nil, common.ParamError("solver header N = %d, M = %d, W = %d, RW = %d", n, m, w, rw)
+	}
+	// 先用除法比较，相乘可能溢出
+	if (m-w)/maxSolverExpansion > n {
+		return nil, common.ParamError("solver M = %d exceeds W + %d·N for N = %d, W = %d", m, maxSolverExpansion, n, w)
+	}
+	s := &band.Solver{N: n, M: m, W: w, RW: rw, VW: 1}
+	if s.Idx, err = readInts(rd, n); err != nil {
+		return nil, err
+	}
+	if s.Pos, err = readInts(rd, n); err != nil {
+		return nil, err
+	}
+	pv, err := readInts(rd, n)
+	if err != nil {
+		return nil, err
+	}
+	nops, err := readInts(rd, 1)
+	if err != nil {
+		return nil, err
+	}
+	if nops[0] < 0 || nops[0]%2 != 0 {
+		return nil, common.ParamError("solver has %d operation entries", nops[0])
+	}
+	if s.Ops, err = readInts(rd, nops[0]); err != nil {
+		return nil, err
+	}
+	if n > math.MaxInt/rw {
+		return nil, common.ParamError("solver rows of N = %d, RW = %d do not fit", n, rw)
+	}
+	if s.Rows, err = readChunks[uint64](rd, n*rw); err != nil {
+		return nil, err
+	}
+
+	seen := make([]bool, n)
+	for i := 0; i < n; i++ {
+		if s.Idx[i] < 0 || s.Idx[i] >= n || seen[s.Idx[i]] {
+			return nil, common.ParamError("solver row %d has index %d", i, s.Idx[i])
+		}
+		seen[s.Idx[i]] = true
+		if s.Pos[i] < 0 || s.Pos[i] > m-w || pv[i] < s.Pos[i] || pv[i] >= s.Pos[i]+w {
+			return nil, common.ParamError("solver row %d has position %d and pivot %d", i, s.Pos[i], pv[i])
+		}
+	}
+	// 行里 W 之上的填充位和与最低位不符的主元会让 BackSubstitute 越界
+	if i := s.Check(pv); i >= 0 {
+		return nil, common.ParamError("solver row %d is not in echelon form with pivot %d", i, pv[i])
+	}
+	for t := 0; t < len(s.Ops); t += 2 {
+		if k, i := s.Ops[t], s.Ops[t+1]; i < 0 || k <= i || k >= n {
+			return nil, common.ParamError("solver operation %d xors row %d into row %d", t/2, i, k)
+		}
+	}
+	return &Solver{N: n, M: m, W: w, s: s, piv: pv}, nil
+}
+
+// readInts 读 k 个小端的 int64，放不进 int 的值返回错误
+func readInts(rd io.Reader, k int) ([]int, error) {
+	buf, err := readChunks[int64](rd, k)
+	if err != nil {
+		return nil, err
+	}
+	dst := make([]int, k)
+	for i, v := range buf {
+		if v < math.MinInt || v > math.MaxInt {
+			return nil, common.FormatError("solver field %d does not fit int", v)
+		}
+		dst[i] = int(v)
+	}
+	return dst, nil
+}
+
+// readChunks 读 k 个小端的 T，每次最多分配 solverChunk 个
+func readChunks[T int64 | uint64](rd io.Reader, k int) ([]T, error) {
+	out := make([]T, 0, min(k, solverChunk))
+	for len(out) < k {
+		buf := make([]T, min(k-len(out), solverChunk))
+		if err := binary.Read(rd, binary.LittleEndian, buf); err != nil {
+			return nil, common.FormatError("solver: %v", err)
+		}
+		out = append(out, buf...)
+	}
+	return out, nil
+}
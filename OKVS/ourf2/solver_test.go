@@ -0,0 +1,85 @@
+package ourf2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	mrand "math/rand"
+	"slices"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+func TestSolverRoundTrip(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(8))
+	keys := testKeys(rng, testN)
+	for _, bitPos := range []bool{false, true} {
+		r, err := NewOKVSBK(testN, okvs.WithSeed(testSeed), okvs.WithBitPositions(bitPos))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sv, err := r.Prepare(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		n, err := sv.WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(buf.Len()) {
+			t.Fatalf("WriteTo returned %d, wrote %d bytes", n, buf.Len())
+		}
+		b := buf.Bytes()
+		rs, err := ReadSolver(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rs.N != sv.N || rs.M != sv.M || rs.W != sv.W {
+			t.Fatalf("N, M, W = %d, %d, %d after the round trip, want %d, %d, %d", rs.N, rs.M, rs.W, sv.N, sv.M, sv.W)
+		}
+
+		vals := make([]uint32, testN)
+		for i := range vals {
+			vals[i] = rng.Uint32()
+		}
+		want, err := sv.Encode(vals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := rs.Encode(vals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("BitPos %v: P from the read Solver differs", bitPos)
+		}
+		r.P = got
+		for i, key := range keys {
+			if v := r.Decode(key); v != vals[i] {
+				t.Fatalf("BitPos %v: Decode = %#x, want %#x", bitPos, v, vals[i])
+			}
+		}
+
+		for _, l := range []int{0, 7, 32, len(b) / 2, len(b) - 1} {
+			if _, err := ReadSolver(bytes.NewReader(b[:l])); !errors.Is(err, okvs.ErrFormat) {
+				t.Errorf("BitPos %v: ReadSolver of %d of %d bytes: got %v, want ErrFormat", bitPos, l, len(b), err)
+			}
+		}
+	}
+}
+
+func TestReadSolverBoundsM(t *testing.T) {
+	// N = 1、W = 64 的 100 字节文件声称 M 接近 2^63
+	le := binary.LittleEndian
+	var b []byte
+	for _, v := range []int64{1, math.MaxInt64, 64, 1} {
+		b = le.AppendUint64(b, uint64(v))
+	}
+	b = append(b, make([]byte, 100-len(b))...)
+	if _, err := ReadSolver(bytes.NewReader(b)); !errors.Is(err, okvs.ErrParams) {
+		t.Fatalf("ReadSolver with M = 2^63-1: got %v, want ErrParams", err)
+	}
+}
@@ -0,0 +1,91 @@
+package ourf2
+
+import (
+	"math/big"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+func init() {
+	okvs.Register("ourf2", func(n int, opts ...okvs.Option) (okvs.Store, error) {
+		r, err := NewOKVSBK(n, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+type storeOKVSBK struct{ r *OKVSBK }
+
+// AsStore 把 OKVSBK 包装成 Store
+func (r *OKVSBK) AsStore() okvs.Store { return storeOKVSBK{r} }
+
+func (s storeOKVSBK) Encode(pairs []okvs.Pair) error {
+	kvs := make([]KVBK, len(pairs))
+	for i := range pairs {
+		v, err := common.ToUint32(pairs[i].Value)
+		if err != nil {
+			return err
+		}
+		kvs[i] = KVBK{Key: pairs[i].Key, Value: v}
+	}
+	_, err := s.r.Encode(kvs)
+	return err
+}
+
+func (s storeOKVSBK) Decode(key []byte) *big.Int {
+	return new(big.Int).SetUint64(uint64(s.r.Decode(key)))
+}
+
+func (s storeOKVSBK) DecodeBatch(keys [][]byte) []*big.Int { return common.DecodeBatch(keys, s.Decode) }
+
+func (s storeOKVSBK) Size() int { return len(s.r.P) * 4 }
+
+func (s storeOKVSBK) Params() okvs.Params { return okvs.Params{N: s.r.N, M: s.r.M, W: s.r.W, R: s.r.R} }
+
+func init() {
+	okvs.Register("ourf2wide", func(n int, opts ...okvs.Option) (okvs.Store, error) {
+		r, err := NewOKVSBKW(n, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return r.AsStore(), nil
+	})
+}
+
+type storeOKVSBKW struct{ r *OKVSBKW }
+
+// AsStore 把 OKVSBKW 包装成 Store，big.Int value 按大端写成 L 个字节
+func (r *OKVSBKW) AsStore() okvs.Store { return storeOKVSBKW{r} }
+
+func (s storeOKVSBKW) Encode(pairs []okvs.Pair) error {
+	kvs := make([]KVBKW, len(pairs))
+	for i := range pairs {
+		v := pairs[i].Value
+		if v == nil {
+			return common.NilValue(i)
+		}
+		if v.Sign() < 0 || v.BitLen() > 8*s.r.L {
+			return okvs.ErrValueRange
+		}
+		kvs[i] = KVBKW{Key: pairs[i].Key, Value: v.FillBytes(make([]byte, s.r.L))}
+	}
+	_, err := s.r.Encode(kvs)
+	return err
+}
+
+func (s storeOKVSBKW) Decode(key []byte) *big.Int {
+	return new(big.Int).SetBytes(s.r.Decode(key))
+}
+
+func (s storeOKVSBKW) DecodeBatch(keys [][]byte) []*big.Int {
+	return common.DecodeBatch(keys, s.Decode)
+}
+
+func (s storeOKVSBKW) Size() int { return s.r.M * s.r.L }
+
+func (s storeOKVSBKW) Params() okvs.Params {
+	return okvs.Params{N: s.r.N, M: s.r.M, W: s.r.W, R: s.r.R}
+}
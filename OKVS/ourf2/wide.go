@@ -0,0 +1,228 @@
+package ourf2
+
+import (
+	"encoding/binary"
+	"io"
+	"math/bits"
+	"sync"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/internal/band"
+	"github.com/OurOKVS/OKVS/internal/common"
+)
+
+// DefaultValueSize 是 OKVSBKW 默认的 value 字节数（128 位）
+const DefaultValueSize = 16
+
+// OKVSBKW is OKVSBK with values of any fixed byte length L. Every value is
+// packed little-endian into VW = (L+7)/8 words and P is one flat array of M
+// such slots, so decoding XORs whole words. L = 8 stores uint64 values, 16
+// and 32 the 128- and 256-bit values of PSI and OPRF, and any other L a
+// fixed-length byte string.
+type OKVSBKW struct {
+	N    int //okvs存储的k-v长度
+	M    int //okvs的实际长度
+	W    int //随机块的长度
+	B    int //桶的个度
+	R    int // hashrange
+	L    int // value 的字节数
+	VW   int // 每个 value 占的 word 数
+	P    []uint64
+	Seed []byte    // hash 种子，为空时等价于不加 key 的 blake2b
+	Tag  []byte    // 域分离标签
+	Rand io.Reader // 非空时用它随机填充非主元位置，使 OKVS 看起来是均匀随机的
+	// BitPos 和 OKVSBK.BitPos 含义相同
+	BitPos bool
+	// Hash 和 OKVSBK.Hash 含义相同
+	Hash okvs.HashMode
+}
+
+type KVBKW struct {
+	Key   []byte //key
+	Value []byte //value，长度必须是 L
+}
+
+// NewOKVSBKW 和 NewOKVSBK 一样，value 长度取自 okvs.WithValueSize，默认 DefaultValueSize
+func NewOKVSBKW(n int, opts ...okvs.Option) (*OKVSBKW, error) {
+	c := okvs.NewConfig(opts...)
+	m, err := c.Size(n)
+	if err != nil {
+		return nil, err
+	}
+	l := c.ValueSize
+	if l == 0 {
+		l = DefaultValueSize
+	}
+	if l < 0 {
+		return nil, common.ParamError("value size %d must be positive", l)
+	}
+	vw := (l + 7) / 8
+	r := &OKVSBKW{
+		N:      n,
+		M:      m,
+		W:      c.W,
+		B:      c.W / 8,
+		R:      m - c.W,
+		L:      l,
+		VW:     vw,
+		P:      make([]uint64, m*vw),
+		Seed:   c.Seed,
+		Tag:    c.Tag,
+		Rand:   c.Rand,
+		BitPos: c.BitPos,
+		Hash:   c.Hash,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate 检查参数是否一致
+func (r *OKVSBKW) Validate() error {
+	if r.L <= 0 || r.VW != (r.L+7)/8 {
+		return common.ParamError("L = %d, VW = %d, want VW = (L+7)/8", r.L, r.VW)
+	}
+	if len(r.P) != r.M*r.VW {
+		return common.ParamError("len(P) = %d, want M*VW = %d", len(r.P), r.M*r.VW)
+	}
+	if err := common.CheckParams(r.N, r.M, r.W, r.R, r.M, r.Seed); err != nil {
+		return err
+	}
+	if r.B != r.W/8 {
+		return common.ParamError("B = %d, want W/8 = %d", r.B, r.W/8)
+	}
+	return r.Hash.Check(r.R)
+}
+
+func (r *OKVSBKW) pos(key []byte) int {
+	pos := r.Hash.Pos(r.Seed, r.Tag, key, r.R)
+	if !r.BitPos {
+		pos = pos / 8 * 8
+	}
+	return pos
+}
+
+func (r *OKVSBKW) hash2(key []byte) []byte {
+	return okvs.HashWithSeed(r.W/8, r.Seed, r.Tag, key)
+}
+
+// Encode 求解 P，kvs 中每个 value 的长度必须是 L
+func (r *OKVSBKW) Encode(kvs []KVBKW) (*OKVSBKW, error) {
+	if len(kvs) != r.N {
+		return nil, common.SizeMismatch(r.N, len(kvs))
+	}
+	for i := range kvs {
+		if len(kvs[i].Value) != r.L {
+			return nil, common.ValueSize(i, len(kvs[i].Value), r.L)
+		}
+	}
+	pos := make([]int, r.N)
+	common.ParallelFor(r.N, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			pos[i] = r.pos(kvs[i].Key)
+		}
+	})
+	s := band.New(r.M, r.W, r.VW, pos)
+	common.ParallelFor(r.N, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			kv := &kvs[s.Idx[i]]
+			band.SetBytes(s.Row(i), r.hash2(kv.Key), r.W)
+			PackValue(s.Val(i), kv.Value)
+		}
+	})
+	piv, fail := s.Eliminate()
+	if fail >= 0 {
+		return nil, &okvs.SingularError{Row: fail, Key: kvs[s.Idx[fail]].Key}
+	}
+	for j := range r.P {
+		r.P[j] = 0
+	}
+	if r.Rand != nil {
+		if err := common.FillWords(r.Rand, r.P, r.VW, 8*r.L, piv); err != nil {
+			return nil, err
+		}
+	}
+	s.BackSubstitute(piv, r.P)
+	return r, nil
+}
+
+// EncodeWithRetry 和 OKVSBK.EncodeWithRetry 一样
+func (r *OKVSBKW) EncodeWithRetry(kvs []KVBKW, retries int) (*OKVSBKW, error) {
+	err := common.EncodeWithRetry(retries, &r.Seed, func() error {
+		_, err := r.Encode(kvs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DecodeWords writes the packed value stored under key into dst, which
+// must hold VW words. It allocates nothing beyond the hashes.
+func (r *OKVSBKW) DecodeWords(dst []uint64, key []byte) {
+	for t := range dst {
+		dst[t] = 0
+	}
+	pos := r.pos(key)
+	vw := r.VW
+	for t, b := range r.hash2(key) {
+		for b != 0 {
+			j := bits.LeadingZeros8(b)
+			b &^= 0x80 >> j
+			col := pos + 8*t + j
+			src := r.P[col*vw : (col+1)*vw]
+			for u := range dst {
+				dst[u] ^= src[u]
+			}
+		}
+	}
+}
+
+// Decode 返回 key 对应的 L 字节 value
+func (r *OKVSBKW) Decode(key []byte) []byte {
+	words := make([]uint64, r.VW)
+	r.DecodeWords(words, key)
+	res := make([]byte, r.L)
+	UnpackValue(res, words)
+	return res
+}
+
+func (r *OKVSBKW) ParDecode(kvs []KVBKW) ([][]byte, error) {
+	res := make([][]byte, len(kvs))
+	var once sync.Once
+	var err error
+	common.ParallelFor(len(kvs), func(lo, hi int) {
+		for j := lo; j < hi; j++ {
+			res[j] = r.Decode(kvs[j].Key)
+			if string(res[j]) != string(kvs[j].Value) {
+				once.Do(func() { err = common.ValueMismatch(j, kvs[j].Key) })
+			}
+		}
+	})
+	return res, err
+}
+
+// PackValue 把 value 按小端放进 dst 的 word 里，末尾不足一个 word 的部分补零
+func PackValue(dst []uint64, v []byte) {
+	for t := range dst {
+		var buf [8]byte
+		if 8*t < len(v) {
+			copy(buf[:], v[8*t:])
+		}
+		dst[t] = binary.LittleEndian.Uint64(buf[:])
+	}
+}
+
+// UnpackValue 是 PackValue 的逆操作，写满 dst
+func UnpackValue(dst []byte, words []uint64) {
+	var buf [8]byte
+	for t := range words {
+		if 8*t >= len(dst) {
+			break
+		}
+		binary.LittleEndian.PutUint64(buf[:], words[t])
+		copy(dst[8*t:], buf[:])
+	}
+}
@@ -0,0 +1,79 @@
+package ourf2
+
+import (
+	"bytes"
+	"fmt"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+// Decode 对 P 是线性的：两个参数相同（N、M、W、R、Seed、Tag、BitPos、Hash）的 OKVSBK
+// 把 P 逐位置异或后，Decode 得到两者 value 的异或。下面的操作都不修改参数。
+
+// Compatible 检查 o 和 r 是否可以逐位置组合，起始位置的 hash 方式和范围也必须相同
+func (r *OKVSBK) Compatible(o *OKVSBK) error {
+	switch {
+	case r.N != o.N || r.M != o.M || r.W != o.W || len(r.P) != len(o.P):
+		return fmt.Errorf("%w: N, M, W = %d, %d, %d and %d, %d, %d", okvs.ErrIncompatible, r.N, r.M, r.W, o.N, o.M, o.W)
+	case !bytes.Equal(r.Seed, o.Seed) || !bytes.Equal(r.Tag, o.Tag):
+		return fmt.Errorf("%w: different seed or tag", okvs.ErrIncompatible)
+	case r.BitPos != o.BitPos:
+		return fmt.Errorf("%w: BitPos = %v and %v", okvs.ErrIncompatible, r.BitPos, o.BitPos)
+	case r.Hash != o.Hash || r.R != o.R:
+		return fmt.Errorf("%w: Hash, R = %v, %d and %v, %d", okvs.ErrIncompatible, r.Hash, r.R, o.Hash, o.R)
+	}
+	return nil
+}
+
+// Xor 返回 Decode 得到两者 value 异或的 OKVSBK
+func (r *OKVSBK) Xor(o *OKVSBK) (*OKVSBK, error) {
+	return XorCombination(r, o)
+}
+
+// XorCombination 返回 Decode 得到所有 vs 的 value 异或的 OKVSBK，vs 至少要有一个
+func XorCombination(vs ...*OKVSBK) (*OKVSBK, error) {
+	if len(vs) == 0 {
+		return nil, fmt.Errorf("%w: nothing to combine", okvs.ErrIncompatible)
+	}
+	for _, v := range vs[1:] {
+		if err := vs[0].Compatible(v); err != nil {
+			return nil, err
+		}
+	}
+	res := *vs[0]
+	res.P = append([]uint32(nil), vs[0].P...)
+	for _, v := range vs[1:] {
+		for j := range res.P {
+			res.P[j] ^= v.P[j]
+		}
+	}
+	return &res, nil
+}
+
+// Compatible 检查 o 和 r 是否可以逐位置组合，起始位置的 hash 方式和范围也必须相同
+func (r *OKVSBKW) Compatible(o *OKVSBKW) error {
+	switch {
+	case r.N != o.N || r.M != o.M || r.W != o.W || r.L != o.L || len(r.P) != len(o.P):
+		return fmt.Errorf("%w: N, M, W, L = %d, %d, %d, %d and %d, %d, %d, %d", okvs.ErrIncompatible, r.N, r.M, r.W, r.L, o.N, o.M, o.W, o.L)
+	case !bytes.Equal(r.Seed, o.Seed) || !bytes.Equal(r.Tag, o.Tag):
+		return fmt.Errorf("%w: different seed or tag", okvs.ErrIncompatible)
+	case r.BitPos != o.BitPos:
+		return fmt.Errorf("%w: BitPos = %v and %v", okvs.ErrIncompatible, r.BitPos, o.BitPos)
+	case r.Hash != o.Hash || r.R != o.R:
+		return fmt.Errorf("%w: Hash, R = %v, %d and %v, %d", okvs.ErrIncompatible, r.Hash, r.R, o.Hash, o.R)
+	}
+	return nil
+}
+
+// Xor 返回 Decode 得到两者 value 异或的 OKVSBKW
+func (r *OKVSBKW) Xor(o *OKVSBKW) (*OKVSBKW, error) {
+	if err := r.Compatible(o); err != nil {
+		return nil, err
+	}
+	res := *r
+	res.P = make([]uint64, len(r.P))
+	for j := range res.P {
+		res.P[j] = r.P[j] ^ o.P[j]
+	}
+	return &res, nil
+}
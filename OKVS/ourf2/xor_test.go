@@ -0,0 +1,124 @@
+package ourf2
+
+import (
+	"errors"
+	mrand "math/rand"
+	"testing"
+
+	okvs "github.com/OurOKVS/OKVS"
+)
+
+func TestXor(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(1))
+	keys := testKeys(rng, testN)
+	for _, bitPos := range []bool{false, true} {
+		a, va := encodeBK(t, rng, keys, okvs.WithBitPositions(bitPos))
+		b, vb := encodeBK(t, rng, keys, okvs.WithBitPositions(bitPos))
+		c, vc := encodeBK(t, rng, keys, okvs.WithBitPositions(bitPos))
+		x, err := a.Xor(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xs, err := XorCombination(a, b, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, key := range keys {
+			if got, want := x.Decode(key), va[i]^vb[i]; got != want {
+				t.Fatalf("BitPos %v: Decode(a.Xor(b)) = %#x, want %#x", bitPos, got, want)
+			}
+			if got, want := xs.Decode(key), va[i]^vb[i]^vc[i]; got != want {
+				t.Fatalf("BitPos %v: Decode(XorCombination(a, b, c)) = %#x, want %#x", bitPos, got, want)
+			}
+			if a.Decode(key) != va[i] {
+				t.Fatal("Xor modified its operand")
+			}
+		}
+	}
+}
+
+func TestXorWide(t *testing.T) {
+	rng := mrand.New(mrand.NewSource(2))
+	keys := testKeys(rng, testN)
+	enc := func() (*OKVSBKW, [][]byte) {
+		r, err := NewOKVSBKW(testN, okvs.WithSeed(testSeed), okvs.WithValueSize(20), okvs.WithRand(rng))
+		if err != nil {
+			t.Fatal(err)
+		}
+		kvs := make([]KVBKW, testN)
+		vals := make([][]byte, testN)
+		for i := range kvs {
+			vals[i] = make([]byte, r.L)
+			rng.Read(vals[i])
+			kvs[i] = KVBKW{Key: keys[i], Value: vals[i]}
+		}
+		if _, err := r.Encode(kvs); err != nil {
+			t.Fatal(err)
+		}
+		return r, vals
+	}
+	a, va := enc()
+	b, vb := enc()
+	x, err := a.Xor(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, key := range keys {
+		got := x.Decode(key)
+		for j := range got {
+			if got[j] != va[i][j]^vb[i][j] {
+				t.Fatalf("Decode(a.Xor(b)) = %x, want %x ^ %x", got, va[i], vb[i])
+			}
+		}
+	}
+}
+
+func TestXorIncompatible(t *testing.T) {
+	base := []okvs.Option{okvs.WithSeed(testSeed)}
+	a, err := NewOKVSBK(testN, base...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mk := func(n int, opts ...okvs.Option) *OKVSBK {
+		r, err := NewOKVSBK(n, append(append([]okvs.Option(nil), base...), opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	otherR := *a
+	otherR.R--
+	for name, o := range map[string]*OKVSBK{
+		"N":      mk(testN + 1),
+		"W":      mk(testN, okvs.WithBandWidth(a.W+8)),
+		"seed":   mk(testN, okvs.WithSeed([]byte("another seed"))),
+		"tag":    mk(testN, okvs.WithTag([]byte("tag"))),
+		"BitPos": mk(testN, okvs.WithBitPositions(true)),
+		"Hash":   mk(testN, okvs.WithHashMode(okvs.Hash32)),
+		"R":      &otherR,
+	} {
+		if _, err := a.Xor(o); !errors.Is(err, okvs.ErrIncompatible) {
+			t.Errorf("Xor with a different %s: got %v, want ErrIncompatible", name, err)
+		}
+		if _, err := XorCombination(a, a, o); !errors.Is(err, okvs.ErrIncompatible) {
+			t.Errorf("XorCombination with a different %s: got %v, want ErrIncompatible", name, err)
+		}
+	}
+	if _, err := XorCombination(); !errors.Is(err, okvs.ErrIncompatible) {
+		t.Errorf("XorCombination(): got %v, want ErrIncompatible", err)
+	}
+
+	w, _ := NewOKVSBKW(testN, base...)
+	for name, opts := range map[string][]okvs.Option{
+		"L":    {okvs.WithValueSize(8)},
+		"Hash": {okvs.WithHashMode(okvs.Hash32)},
+	} {
+		o, err := NewOKVSBKW(testN, append(append([]okvs.Option(nil), base...), opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Xor(o); !errors.Is(err, okvs.ErrIncompatible) {
+			t.Errorf("OKVSBKW.Xor with a different %s: got %v, want ErrIncompatible", name, err)
+		}
+	}
+}
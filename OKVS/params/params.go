@@ -0,0 +1,311 @@
+// Package params recommends OKVS sizes and band widths from a target failure
+// probability, backed by a Monte Carlo estimate of the real Encode.
+package params
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"runtime"
+	"sync"
+
+	okvs "github.com/OurOKVS/OKVS"
+	"github.com/OurOKVS/OKVS/fp"
+	"github.com/OurOKVS/OKVS/internal/common"
+	"github.com/OurOKVS/OKVS/ourf2"
+)
+
+// FieldKind 表示 OKVS 的 value 所在的域
+type FieldKind int
+
+const (
+	FieldGF2 FieldKind = iota // 二元域，对应 OKVSBK
+	FieldFp                   // 素数域，对应 OKVSFp
+)
+
+const (
+	DefaultTrials  = 200     // Estimate 对每组参数默认的试验次数
+	DefaultSampleN = 1 << 12 // Recommend 标定时默认的最大 n
+)
+
+// DefaultExpansions 是 Recommend 默认搜索的扩张率
+var DefaultExpansions = []float64{okvs.DefaultExpansion, 1.1, 1.2, 1.3, 1.5}
+
+// ErrCalibration 表示标定得到的失败率不足以外推出参数
+var ErrCalibration = errors.New("okvs: not enough failures observed to calibrate")
+
+// mersenne61 是 FieldFp 试验使用的素数 2^61-1
+var mersenne61 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 61), big.NewInt(1))
+
+// Estimate is the empirical failure rate of Encode for one parameter set,
+// with a 95% Wilson score interval [Lower, Upper].
+type Estimate struct {
+	okvs.Params
+	Trials   int
+	Failures int
+	Rate     float64
+	Lower    float64
+	Upper    float64
+}
+
+// Recommendation is the band width Recommend picks for one expansion ratio.
+// Log2Rate is the fitted log2 failure probability at W. It is a measurement
+// only when Extrapolated is false; otherwise W lies beyond the widths that
+// showed failures, or n beyond the sample size, and the value comes from
+// extending the fitted line.
+type Recommendation struct {
+	okvs.Params
+	Expansion    float64
+	Log2Rate     float64
+	Extrapolated bool
+	Samples      []Estimate // 标定时测量的每个 W
+}
+
+// Calculator recommends sizes and band widths by running the real Encode
+// elimination on random keys and extrapolating the observed failure rates.
+type Calculator struct {
+	Field      FieldKind
+	Expansions []float64 // Recommend 搜索的扩张率，默认 DefaultExpansions
+	Trials     int       // 每个 W 的试验次数，默认 DefaultTrials
+	SampleN    int       // 标定时使用的最大 n，默认 DefaultSampleN
+	BitPos     bool      // GF(2) 时使用精确到 bit 的起始位置，见 ourf2.OKVSBK.BitPos
+	RandCoef   bool      // Fp 时使用随机带内系数，见 fp.OKVSFp.RandCoef
+	// Rand 生成试验的 key 和 seed，默认 crypto/rand。固定它可以复现一次标定
+	Rand io.Reader
+}
+
+func (c Calculator) expansions() []float64 {
+	if len(c.Expansions) == 0 {
+		return DefaultExpansions
+	}
+	return c.Expansions
+}
+
+func (c Calculator) rand() io.Reader {
+	if c.Rand == nil {
+		return rand.Reader
+	}
+	return c.Rand
+}
+
+func (c Calculator) trials() int {
+	if c.Trials <= 0 {
+		return DefaultTrials
+	}
+	return c.Trials
+}
+
+// Estimate runs Encode Trials times on n random keys with a fresh seed each
+// time and counts how often the band system turns out singular. Keys and
+// seeds are drawn from Rand before any trial starts.
+func (c Calculator) Estimate(n, m, w int) (Estimate, error) {
+	est := Estimate{Params: okvs.Params{N: n, M: m, W: w, R: m - w}, Trials: c.trials()}
+	if err := common.CheckParams(n, m, w, m-w, m, nil); err != nil {
+		return est, err
+	}
+	// key 和 seed 都在这里按顺序读出来，同一个 Rand 得到同样的结果
+	rnd := c.rand()
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = make([]byte, 16)
+		if _, err := io.ReadFull(rnd, keys[i]); err != nil {
+			return est, err
+		}
+	}
+	seeds := make([][]byte, est.Trials)
+	for t := range seeds {
+		seeds[t] = make([]byte, okvs.SeedSize)
+		if _, err := io.ReadFull(rnd, seeds[t]); err != nil {
+			return est, err
+		}
+	}
+
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	next := make(chan []byte)
+	for t := 0; t < runtime.NumCPU(); t++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range next {
+				err := c.trial(keys, seed, m, w)
+				mu.Lock()
+				if errors.Is(err, okvs.ErrSingularSystem) {
+					est.Failures++
+				} else if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, seed := range seeds {
+		next <- seed
+	}
+	close(next)
+	wg.Wait()
+	if firstErr != nil {
+		return est, firstErr
+	}
+	est.Rate = float64(est.Failures) / float64(est.Trials)
+	est.Lower, est.Upper = wilson(est.Failures, est.Trials)
+	return est, nil
+}
+
+// trial 用 seed 对 keys 编码一次
+func (c Calculator) trial(keys [][]byte, seed []byte, m, w int) error {
+	var err error
+	n := len(keys)
+	switch c.Field {
+	case FieldGF2:
+		r := &ourf2.OKVSBK{N: n, M: m, W: w, B: w / 8, R: m - w, P: make([]uint32, m), Seed: seed, BitPos: c.BitPos}
+		kvs := make([]ourf2.KVBK, n)
+		for i := range kvs {
+			kvs[i].Key = keys[i]
+		}
+		_, err = r.Encode(kvs)
+	case FieldFp:
+		r := &fp.OKVSFp{N: n, M: m, W: w, P: make([]*big.Int, m), Q: mersenne61, Seed: seed, RandCoef: c.RandCoef}
+		kvs := make([]fp.KVFp, n)
+		for i := range kvs {
+			kvs[i] = fp.KVFp{Key: new(big.Int).SetBytes(keys[i]), Value: new(big.Int)}
+		}
+		_, err = r.Encode(kvs)
+	default:
+		err = common.ParamError("unknown field %d", c.Field)
+	}
+	return err
+}
+
+// wilson 返回 95% 置信度的 Wilson 区间
+func wilson(failures, trials int) (float64, float64) {
+	const z = 1.959964
+	t := float64(trials)
+	p := float64(failures) / t
+	denom := 1 + z*z/t
+	center := (p + z*z/(2*t)) / denom
+	half := z * math.Sqrt(p*(1-p)/t+z*z/(4*t*t)) / denom
+	lo, hi := math.Max(0, center-half), math.Min(1, center+half)
+	// 端点上舍入误差会让区间差一点点碰不到 0 或 1
+	if failures == 0 {
+		lo = 0
+	}
+	if failures == trials {
+		hi = 1
+	}
+	return lo, hi
+}
+
+// Recommend returns, for each expansion ratio of Expansions, the (M, W) for
+// n keys such that Encode fails with probability about 2^-lambda, ordered as
+// Expansions. A larger expansion costs a larger M and buys a narrower W.
+//
+// The failure probability decays exponentially in W, so for each expansion
+// Recommend measures it with Estimate on min(n, SampleN) keys for W = 8, 16,
+// ... until no more failures are seen, fits log2(rate) linearly in W,
+// scales the fit by n over the sample size (union bound over rows) and
+// solves for the target. Expansions whose rates cannot be fitted, or whose
+// W does not fit in M, are left out; if none is left Recommend returns
+// ErrCalibration.
+func (c Calculator) Recommend(n int, lambda float64) ([]Recommendation, error) {
+	var res []Recommendation
+	for _, e := range c.expansions() {
+		r, err := c.recommend(n, e, lambda)
+		if errors.Is(err, ErrCalibration) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	if len(res) == 0 {
+		return nil, ErrCalibration
+	}
+	return res, nil
+}
+
+// recommend 对一个扩张率标定并求出 W，W 放不进 M 时也返回 ErrCalibration
+func (c Calculator) recommend(n int, e, lambda float64) (Recommendation, error) {
+	rec := Recommendation{Expansion: e}
+	cfg := okvs.Config{Expansion: e}
+	m, err := cfg.Size(n)
+	if err != nil {
+		return rec, err
+	}
+	sn := c.SampleN
+	if sn <= 0 {
+		sn = DefaultSampleN
+	}
+	if sn > n {
+		sn = n
+	}
+	sm, err := cfg.Size(sn)
+	if err != nil {
+		return rec, err
+	}
+
+	var xs, ys []float64
+	for w := 8; w < sm-sn/2; w += 8 {
+		est, err := c.Estimate(sn, sm, w)
+		if err != nil {
+			return rec, err
+		}
+		rec.Samples = append(rec.Samples, est)
+		if est.Failures == 0 {
+			break
+		}
+		if est.Failures < est.Trials {
+			xs = append(xs, float64(w))
+			ys = append(ys, math.Log2(est.Rate))
+		}
+	}
+	slope, intercept, ok := fitLine(xs, ys)
+	if !ok || slope >= 0 {
+		return rec, ErrCalibration
+	}
+
+	// log2 P(n, w) ≈ intercept + slope*w + log2(n/sn)
+	scale := math.Log2(float64(n) / float64(sn))
+	target := -lambda - intercept - scale
+	w := int(math.Ceil(target/slope/8)) * 8
+	if w < 8 {
+		w = 8
+	}
+	if w >= m {
+		return rec, fmt.Errorf("%w: W = %d does not fit in M = %d", ErrCalibration, w, m)
+	}
+	rec.Params = okvs.Params{N: n, M: m, W: w, R: m - w}
+	rec.Log2Rate = intercept + slope*float64(w) + scale
+	rec.Extrapolated = float64(w) > xs[len(xs)-1] || n > sn
+	if err := common.CheckParams(n, m, w, m-w, m, nil); err != nil {
+		return rec, err
+	}
+	return rec, nil
+}
+
+// fitLine 用最小二乘拟合 y = slope*x + intercept，至少需要两个点
+func fitLine(xs, ys []float64) (slope, intercept float64, ok bool) {
+	n := float64(len(xs))
+	if len(xs) < 2 {
+		return 0, 0, false
+	}
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	d := n*sxx - sx*sx
+	if d == 0 {
+		return 0, 0, false
+	}
+	slope = (n*sxy - sx*sy) / d
+	intercept = (sy - slope*sx) / n
+	return slope, intercept, true
+}
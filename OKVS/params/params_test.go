@@ -0,0 +1,70 @@
+package params
+
+import (
+	"math"
+	mrand "math/rand"
+	"testing"
+)
+
+func TestWilson(t *testing.T) {
+	for _, tc := range []struct {
+		failures, trials int
+		lower, upper     float64
+	}{
+		{10, 100, 0.055229, 0.174366},
+		{0, 50, 0, 0.071348},
+		{50, 50, 0.928652, 1},
+	} {
+		lo, hi := wilson(tc.failures, tc.trials)
+		if math.Abs(lo-tc.lower) > 1e-6 || math.Abs(hi-tc.upper) > 1e-6 {
+			t.Fatalf("wilson(%d, %d) = [%g, %g], want [%g, %g]", tc.failures, tc.trials, lo, hi, tc.lower, tc.upper)
+		}
+		if p := float64(tc.failures) / float64(tc.trials); p < lo || p > hi {
+			t.Fatalf("wilson(%d, %d) = [%g, %g] does not contain %g", tc.failures, tc.trials, lo, hi, p)
+		}
+	}
+}
+
+// W = 8 的 256 个 key 几乎不可能解出来，每次试验都应该失败
+func TestEstimateTinyW(t *testing.T) {
+	for _, f := range []FieldKind{FieldGF2, FieldFp} {
+		c := Calculator{Field: f, Trials: 20, Rand: mrand.New(mrand.NewSource(1))}
+		est, err := c.Estimate(256, 264, 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if est.Failures != est.Trials || est.Rate != 1 {
+			t.Fatalf("field %d: failures = %d/%d, want all", f, est.Failures, est.Trials)
+		}
+		if est.Upper != 1 || est.Lower < 0.8 {
+			t.Fatalf("field %d: interval [%g, %g], want [>0.8, 1]", f, est.Lower, est.Upper)
+		}
+	}
+}
+
+// 同一个 Rand 得到同一次标定，lambda 越大 W 越宽，拟合的失败率也不超过 2^-lambda
+func TestRecommendMonotone(t *testing.T) {
+	const n = 1 << 14
+	prev := 0
+	for _, lambda := range []float64{10, 20, 40, 80} {
+		c := Calculator{Field: FieldGF2, Expansions: []float64{1.3}, Trials: 100, SampleN: 256, Rand: mrand.New(mrand.NewSource(2))}
+		recs, err := c.Recommend(n, lambda)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(recs) != 1 {
+			t.Fatalf("lambda = %g: got %d recommendations, want 1", lambda, len(recs))
+		}
+		r := recs[0]
+		if r.W < prev {
+			t.Fatalf("lambda = %g: W = %d, narrower than %d for a smaller lambda", lambda, r.W, prev)
+		}
+		if r.Log2Rate > -lambda {
+			t.Fatalf("lambda = %g: fitted log2 rate %g above the target", lambda, r.Log2Rate)
+		}
+		if !r.Extrapolated {
+			t.Fatalf("lambda = %g: n = %d > SampleN is not reported as extrapolated", lambda, n)
+		}
+		prev = r.W
+	}
+}
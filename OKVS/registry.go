@@ -0,0 +1,57 @@
+package okvs
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownBackend 表示没有以该名字注册的后端
+var ErrUnknownBackend = errors.New("okvs: unknown backend")
+
+// Factory 用 n 和选项构造一个后端
+type Factory func(n int, opts ...Option) (Store, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a backend available under name. Backend packages call it
+// from init, so importing a backend (even with a blank import) registers it.
+// Register panics if name is registered twice.
+func Register(name string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if f == nil {
+		panic("okvs: Register factory is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("okvs: Register called twice for backend " + name)
+	}
+	registry[name] = f
+}
+
+// New 用名为 name 的后端构造一个 Store
+func New(name string, n int, opts ...Option) (Store, error) {
+	registryMu.RLock()
+	f, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q (forgotten import?)", ErrUnknownBackend, name)
+	}
+	return f(n, opts...)
+}
+
+// Backends 返回已注册的后端名字，按字母序排列
+func Backends() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
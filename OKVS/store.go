@@ -0,0 +1,35 @@
+// Package okvs defines the interface shared by every oblivious key-value
+// store backend, the common options, errors and hashing, and a registry
+// that the backend packages add themselves to.
+package okvs
+
+import "math/big"
+
+// Pair 是所有 Store 后端共用的 key-value 对
+type Pair struct {
+	Key   []byte   //key
+	Value *big.Int //value
+}
+
+// Params 描述一个 OKVS 的形状
+type Params struct {
+	N int //okvs存储的k-v长度
+	M int //okvs的实际长度
+	W int //随机块的长度
+	R int // hashrange
+}
+
+// Store is the interface shared by every OKVS variant, so protocol code can
+// swap the binary-field, big-int and prime-field structures freely.
+type Store interface {
+	// Encode builds P from exactly N pairs.
+	Encode(pairs []Pair) error
+	// Decode returns the value stored under key.
+	Decode(key []byte) *big.Int
+	// DecodeBatch decodes every key in parallel.
+	DecodeBatch(keys [][]byte) []*big.Int
+	// Size returns the size of P in bytes.
+	Size() int
+	// Params returns the parameters of the structure.
+	Params() Params
+}
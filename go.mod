@@ -6,6 +6,5 @@ require (
 	github.com/bits-and-blooms/bitset v1.13.0
 	github.com/tunabay/go-bitarray v1.3.1
 	golang.org/x/crypto v0.21.0
+	golang.org/x/sys v0.19.0
 )
-
-require golang.org/x/sys v0.19.0 // indirect
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"math"
 	"math/big"
 	"math/rand"
 	"os"
@@ -11,6 +10,13 @@ import (
 	"time"
 
 	okvs "github.com/OurOKVS/OKVS"
+	_ "github.com/OurOKVS/OKVS/bigint"
+	"github.com/OurOKVS/OKVS/bpsy23"
+	_ "github.com/OurOKVS/OKVS/buffer"
+	_ "github.com/OurOKVS/OKVS/ecdlp"
+	_ "github.com/OurOKVS/OKVS/fp"
+	"github.com/OurOKVS/OKVS/ourf2"
+	"github.com/OurOKVS/OKVS/params"
 	"github.com/bits-and-blooms/bitset"
 	"github.com/tunabay/go-bitarray"
 )
@@ -73,13 +79,75 @@ func TestXor2() {
 	fmt.Println(e)
 }
 
-func Pdecode(wg *sync.WaitGroup, i int, iend int, kvs []okvs.KV, okvs *okvs.OKVS, n int) {
+func Pdecode(wg *sync.WaitGroup, i int, iend int, kvs []bpsy23.KV, okvs *bpsy23.OKVS, n int) {
 	defer wg.Done()
 	for k := i; k < iend; k++ {
 		okvs.Decode(kvs[k%n].Key).Int64()
 	}
 }
 
+// BenchBackends 用同一组 kv 依次测试所有已注册的后端
+func BenchBackends(n int) {
+	q := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 61), big.NewInt(1))
+	pairs := make([]okvs.Pair, n)
+	keys := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		keys[i] = generateRandomBytes(64)
+		pairs[i] = okvs.Pair{Key: keys[i], Value: big.NewInt(int64(rand.Uint32()))}
+	}
+	for _, name := range okvs.Backends() {
+		store, err := okvs.New(name, n, okvs.WithExpansion(1.1), okvs.WithModulus(q))
+		if err != nil {
+			fmt.Println(name, err)
+			continue
+		}
+		s1 := time.Now()
+		if err := store.Encode(pairs); err != nil {
+			fmt.Println(name, err)
+			continue
+		}
+		end := time.Since(s1)
+		s2 := time.Now()
+		res := store.DecodeBatch(keys)
+		end2 := time.Since(s2)
+		wrong := 0
+		for i := range res {
+			if res[i].Cmp(pairs[i].Value) != 0 {
+				wrong++
+			}
+		}
+		fmt.Printf("%-8s encoding n = %d, time = %s, decoding time = %s, wrong = %d\n", name, n, end, end2, wrong)
+	}
+}
+
+// CompareAlignment 比较 OKVSBK 字节对齐和精确到 bit 的起始位置：
+// 先测编码时间，再用 params.Calculator 估计每个 W 的失败率
+func CompareAlignment(n int, ws []int) {
+	kvs := make([]ourf2.KVBK, n)
+	for i := 0; i < n; i++ {
+		kvs[i] = ourf2.KVBK{Key: generateRandomBytes(64), Value: rand.Uint32()}
+	}
+	for _, bitpos := range []bool{false, true} {
+		r, err := ourf2.NewOKVSBK(n, okvs.WithBitPositions(bitpos))
+		if err != nil {
+			panic(err)
+		}
+		s1 := time.Now()
+		if _, err := r.Encode(kvs); err != nil {
+			panic(err)
+		}
+		fmt.Printf("bitpos = %v, encoding n = %d, time = %s\n", bitpos, n, time.Since(s1))
+		c := params.Calculator{Field: params.FieldGF2, BitPos: bitpos}
+		for _, w := range ws {
+			est, err := c.Estimate(n, r.M, w)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Printf("bitpos = %v, W = %d, failures = %d/%d, rate in [%g, %g]\n", bitpos, w, est.Failures, est.Trials, est.Lower, est.Upper)
+		}
+	}
+}
+
 /*
 func main() {
 	n := 16384
@@ -88,16 +156,16 @@ func main() {
 	m := int(math.Round(float64(n) * e))
 
 	// 创建长度为 n 的 KV 结构体切片
-	kvs := make([]okvs.KV, n)
+	kvs := make([]bpsy23.KV, n)
 	// 输出 KV 结构体切片
 	for i := 0; i < int(n); i++ {
 		key := generateRandomBytes(8)            // 生成长度为8的随机字节切片作为key
 		value := rand.Uint32()                   // 生成随机的uint32切片作为value
-		kvs[i] = okvs.KV{Key: key, Value: value} // 将key和value赋值给KV结构体
+		kvs[i] = bpsy23.KV{Key: key, Value: value} // 将key和value赋值给KV结构体
 	}
 	//fmt.Printf("KV slice: %+v\n", kvs)
 	w := 256
-	okvs := okvs.OKVS{
+	okvs := bpsy23.OKVS{
 		N: n,
 		M: m,
 		W: w,
@@ -141,16 +209,16 @@ func main() {
 	m := int(math.Round(float64(n) * e))
 
 	// 创建长度为 n 的 KV 结构体切片
-	kvs := make([]okvs.KVBF, n)
+	kvs := make([]buffer.KVBF, n)
 
 	// 输出 KV 结构体切片
 	for i := 0; i < int(n); i++ {
 		key := generateRandomBytes(8)              // 生成长度为8的随机字节切片作为key
 		value := rand.Uint32()                     // 生成随机的uint32切片作为value
-		kvs[i] = okvs.KVBF{Key: key, Value: value} // 将key和value赋值给KV结构体
+		kvs[i] = buffer.KVBF{Key: key, Value: value} // 将key和value赋值给KV结构体
 	}
 	//fmt.Printf("KV slice: %+v\n", kvs)
-	okvs := okvs.OKVSBF{
+	okvs := buffer.OKVSBF{
 		N: n,
 		M: m,
 		W: 360,
@@ -186,17 +254,17 @@ func main() {
 	m := int(math.Round(float64(n) * e))
 
 	// 创建长度为 n 的 KV 结构体切片
-	kvs := make([]okvs.KVB, n)
+	kvs := make([]bigint.KVB, n)
 
 	// 输出 KV 结构体切片
 	for i := 0; i < int(n); i++ {
 		key := generateRandomBytes(8)             // 生成长度为8的随机字节切片作为key
 		value, _ := rand1.Prime(rand1.Reader, 32) // 生成随机的uint32切片作为value
-		kvs[i] = okvs.KVB{Key: key, Value: value} // 将key和value赋值给KV结构体
+		kvs[i] = bigint.KVB{Key: key, Value: value} // 将key和value赋值给KV结构体
 	}
 	//fmt.Printf("KV slice: %+v\n", kvs)
 	w := 360
-	okvs := okvs.OKVSB{
+	okvs := bigint.OKVSB{
 		N: n,
 		M: m,
 		W: w,
@@ -241,29 +309,26 @@ func main() {
 	n := 1 << 20
 	//n1 := 1 << 24
 	e := 1.03
-	m := int(math.Round(float64(n) * e))
 
 	// 创建长度为 n 的 KV 结构体切片
-	kvs := make([]okvs.KVBK, n)
+	kvs := make([]ourf2.KVBK, n)
 	// 输出 KV 结构体切片
 	for i := 0; i < int(n); i++ {
-		key := generateRandomBytes(8)              // 生成长度为8的随机字节切片作为key
-		value := rand.Uint32()                     // 生成随机的uint32切片作为value
-		kvs[i] = okvs.KVBK{Key: key, Value: value} // 将key和value赋值给KV结构体
+		key := generateRandomBytes(8)               // 生成长度为8的随机字节切片作为key
+		value := rand.Uint32()                      // 生成随机的uint32切片作为value
+		kvs[i] = ourf2.KVBK{Key: key, Value: value} // 将key和value赋值给KV结构体
 	}
 	//fmt.Printf("KV slice: %+v\n", kvs)
 	w := 600
-	okvs := okvs.OKVSBK{
-		N: n,
-		M: m,
-		W: w,
-		B: w / 8,
-		R: m - w,
-		P: make([]uint32, m),
+	okvs, err := ourf2.NewOKVSBK(n, okvs.WithExpansion(e), okvs.WithBandWidth(w))
+	if err != nil {
+		panic(err)
 	}
 
 	s1 := time.Now()
-	okvs.Encode(kvs)
+	if _, err := okvs.Encode(kvs); err != nil {
+		panic(err)
+	}
 	end := time.Since(s1)
 	fmt.Println("e =", e)
 	fmt.Printf("encoing n = %d, time = %s\n", n, end)
@@ -273,10 +338,14 @@ func main() {
 	//threadnum := 128
 	//block := n / threadnum
 	s2 := time.Now()
-	okvs.ParDecode(kvs)
+	if _, err := okvs.ParDecode(kvs); err != nil {
+		panic(err)
+	}
 	//wg.Wait()
 	end = time.Since(s2)
 	fmt.Printf("decoing n = %d, time = %s\n", n, end)
+	//BenchBackends(1 << 14)
+	//CompareAlignment(1<<14, []int{96, 128, 160})
 }
 
 /*